@@ -2,15 +2,22 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/leanix/leanix-k8s-connector/pkg/delta"
+	"github.com/leanix/leanix-k8s-connector/pkg/fieldpath"
 	"github.com/leanix/leanix-k8s-connector/pkg/kubernetes"
 	"github.com/leanix/leanix-k8s-connector/pkg/leanix"
 	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/leanix/leanix-k8s-connector/pkg/redact"
 	"github.com/leanix/leanix-k8s-connector/pkg/storage"
 	"github.com/leanix/leanix-k8s-connector/pkg/version"
 	flag "github.com/spf13/pflag"
@@ -18,6 +25,8 @@ import (
 
 	"github.com/op/go-logging"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	restclient "k8s.io/client-go/rest"
@@ -26,24 +35,67 @@ import (
 )
 
 const (
-	clusterNameFlag             string = "clustername"
-	storageBackendFlag          string = "storage-backend"
-	azureAccountNameFlag        string = "azure-account-name"
-	azureAccountKeyFlag         string = "azure-account-key"
-	azureContainerFlag          string = "azure-container"
-	localFilePathFlag           string = "local-file-path"
-	verboseFlag                 string = "verbose"
-	connectorIDFlag             string = "connector-id"
-	connectorVersionFlag        string = "connector-version"
-	connectorProcessingModeFlag string = "processing-mode"
-	integrationAPIFlag          string = "integration-api-enabled"
-	integrationAPIFqdnFlag      string = "integration-api-fqdn"
-	integrationAPITokenFlag     string = "integration-api-token"
-	blacklistNamespacesFlag     string = "blacklist-namespaces"
-	lxWorkspaceFlag             string = "lx-workspace"
-	localFlag                   string = "local"
+	clusterNameFlag              string = "clustername"
+	storageBackendFlag           string = "storage-backend"
+	azureAccountNameFlag         string = "azure-account-name"
+	azureAccountKeyFlag          string = "azure-account-key"
+	azureContainerFlag           string = "azure-container"
+	localFilePathFlag            string = "local-file-path"
+	verboseFlag                  string = "verbose"
+	connectorIDFlag              string = "connector-id"
+	connectorVersionFlag         string = "connector-version"
+	connectorProcessingModeFlag  string = "processing-mode"
+	integrationAPIFlag           string = "integration-api-enabled"
+	integrationAPIFqdnFlag       string = "integration-api-fqdn"
+	integrationAPITokenFlag      string = "integration-api-token"
+	blacklistNamespacesFlag      string = "blacklist-namespaces"
+	whitelistNamespacesFlag      string = "whitelist-namespaces"
+	lxWorkspaceFlag              string = "lx-workspace"
+	resourceWhitelistFlag        string = "resource-whitelist"
+	resourceWhitelistFileFlag    string = "resource-whitelist-file"
+	pageSizeFlag                 string = "page-size"
+	workersFlag                  string = "workers"
+	errorPolicyFlag              string = "error-policy"
+	errorThresholdFlag           string = "error-threshold"
+	syncModeFlag                 string = "sync-mode"
+	fullSyncIntervalFlag         string = "full-sync-interval"
+	markRemovedFlag              string = "mark-removed"
+	skipUnchangedFlag            string = "skip-unchanged"
+	redactionRulesFlag           string = "redaction-rules"
+	projectionIncludeFlag        string = "projection-include"
+	projectionExcludeFlag        string = "projection-exclude"
+	keepRawObjectsFlag           string = "keep-raw-objects"
+	typedMappersFlag             string = "typed-mappers"
+	imageInventoryFlag           string = "image-inventory"
+	collapseOwnedFlag            string = "collapse-owned"
+	relationsFlag                string = "relations"
+	namespaceAggregatesFlag      string = "namespace-aggregates"
+	applicationSourcesFlag       string = "application-sources"
+	ownerSourcesFlag             string = "owner-sources"
+	namespaceSelectorFlag        string = "namespace-selector"
+	namespaceExcludeSelectorFlag string = "namespace-exclude-selector"
+	objectSelectorFlag           string = "object-selector"
+	objectExcludeSelectorFlag    string = "object-exclude-selector"
+	nodeLabelsFlag               string = "node-labels"
+	modeFlag                     string = "mode"
+	watchIntervalFlag            string = "watch-interval"
+	watchDebounceFlag            string = "watch-debounce"
+	localFlag                    string = "local"
 )
 
+const (
+	oneShotMode string = "oneshot"
+	watchMode   string = "watch"
+)
+
+const (
+	fullSyncMode  string = "full"
+	deltaSyncMode string = "delta"
+)
+
+// resolvedSection is the spool section holding the mapped objects with resolved controllers and ownership
+const resolvedSection string = "resolved"
+
 const (
 	lxVersion                      string = "1.0.0"
 	lxConnectorID                  string = "Kubernetes"
@@ -70,6 +122,7 @@ func main() {
 	log.Infof("LeanIX connector processing mode: %s", viper.GetString(connectorProcessingModeFlag))
 	log.Infof("Target LeanIX workspace: %s", viper.GetString(lxWorkspaceFlag))
 	log.Infof("Target Kubernetes cluster name: %s", viper.GetString(clusterNameFlag))
+	log.Infof("Connector mode: %s", viper.GetString(modeFlag))
 
 	var config *restclient.Config
 	if viper.GetBool(localFlag) {
@@ -96,109 +149,376 @@ func main() {
 		log.Fatal(err)
 	}
 
-	log.Debug("Get blacklist namespaces list...")
-	blacklistedNamespacesList := viper.GetStringSlice(blacklistNamespacesFlag)
-	blacklistedNamespaces, err := kubernetesAPI.Namespaces(blacklistedNamespacesList)
+	resourceWhitelist, err := loadResourceWhitelist()
+	if err != nil {
+		log.Fatal(err)
+	}
+	errorPolicy, err := kubernetes.NewErrorPolicy(viper.GetString(errorPolicyFlag), viper.GetInt(errorThresholdFlag))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if errorPolicy.Mode == kubernetes.BestEffort && viper.GetString(connectorProcessingModeFlag) != "partial" {
+		// the Integration API deletes the fact sheets of objects missing in a full LDIF
+		log.Fatalf("The %s error policy requires the processing mode partial", kubernetes.BestEffort)
+	}
+	uploader, err := newBackend()
 	if err != nil {
 		log.Fatal(err)
 	}
-	log.Debug("Getting blacklist namespaces list done.")
-	log.Infof("Namespace blacklist: %v", reflect.ValueOf(blacklistedNamespaces).MapKeys())
 
-	resourcesList, err := ServerPreferredListableResources(kubernetesAPI.Client.Discovery())
+	scannedResources, namespacedResources, discoveryReport, err := discoverResources(kubernetesAPI, resourceWhitelist)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = errorPolicy.Check(discoveryReport)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if viper.GetString(modeFlag) == watchMode {
+		watch(kubernetesAPI, dynClient, scannedResources, discoveryReport, errorPolicy, uploader, debugLogBuffer)
+		return
+	}
+
+	objectLabelFilter, err := loadLabelFilter(objectSelectorFlag, objectExcludeSelectorFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	includedNamespaces, excludedNamespaces, err := filterNamespaces(kubernetesAPI)
+	if err != nil {
+		log.Fatal(err)
+	}
+	filter, err := objectFilter(excludedNamespaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+	scope := kubernetes.NewNamespaceScope(includedNamespaces, excludedNamespaces)
+	if scope.PerNamespace {
+		log.Debugf("Listing namespaced resources in %d included namespaces", len(scope.Namespaces))
+	} else if scope.FieldSelector != "" {
+		log.Debugf("Listing resources with field selector %s", scope.FieldSelector)
+	}
+	collector := kubernetes.ResourceCollector{
+		Client:        dynClient,
+		PageSize:      viper.GetInt64(pageSizeFlag),
+		Workers:       viper.GetInt(workersFlag),
+		LabelSelector: objectLabelFilter.ListSelector(),
+		Scope:         scope,
+		Namespaced:    namespacedResources,
+		Filter:        filter,
+		StopOnError:   errorPolicy.StopOnError(),
+	}
+	runReport := copyReport(discoveryReport)
+	spool, err := storage.NewSpool("")
 	if err != nil {
 		log.Fatal(err)
 	}
+	collect := func(handle kubernetes.PageHandler) []kubernetes.CollectResult {
+		return collector.Collect(scannedResources, handle)
+	}
+	ldif, err := buildLdif(kubernetesAPI, collect, spool, runReport, errorPolicy)
+	if err == nil {
+		err = syncLdif(uploader, ldif, runReport)
+	}
+	closeSpool(spool)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Debug("-----------End-----------")
+	err = uploader.UploadLog(debugLogBuffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Info("-----------End-----------")
+}
+
+// newBackend creates the storage backend configured by the storage backend flags
+func newBackend() (storage.Backend, error) {
+	azureOpts := storage.AzureBlobOpts{
+		AccountName: viper.GetString(azureAccountNameFlag),
+		AccountKey:  viper.GetString(azureAccountKeyFlag),
+		Container:   viper.GetString(azureContainerFlag),
+	}
+	localFileOpts := storage.LocalFileOpts{
+		Path: viper.GetString(localFilePathFlag),
+	}
+	return storage.NewBackend(viper.GetString(storageBackendFlag), &azureOpts, &localFileOpts)
+}
+
+// discoverResources returns the whitelisted resources served by the cluster sorted by group, version and resource
+// and the set of namespaced resources. API groups that failed to be discovered are recorded in the returned report.
+func discoverResources(kubernetesAPI *kubernetes.API, resourceWhitelist kubernetes.ResourceWhitelist) ([]schema.GroupVersionResource, map[schema.GroupVersionResource]bool, *kubernetes.RunReport, error) {
+	report := kubernetes.NewRunReport()
+	resourcesList, err := ServerPreferredListableResources(kubernetesAPI.Client.Discovery())
+	if discoveryErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		for gv, groupErr := range discoveryErr.Groups {
+			if !resourceWhitelist.ContainsGroupVersion(gv) {
+				log.Debugf("Ignoring failed discovery of not whitelisted group version %s: %s", gv, groupErr)
+				continue
+			}
+			log.Warningf("Failed to discover resources of %s: %s", gv, groupErr)
+			report.AddDiscoveryFailure(gv, groupErr)
+		}
+	} else if err != nil {
+		return nil, nil, nil, err
+	}
 	groupVersionResources, err := discovery.GroupVersionResources(resourcesList)
 	if err != nil {
-		log.Panic(err)
+		return nil, nil, nil, err
+	}
+	namespacedResources, err := kubernetes.NamespacedResources(resourcesList)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, e := range resourceWhitelist.Unmatched(groupVersionResources) {
+		log.Warningf("Resource whitelist entry %s does not match any resource served by the cluster", e)
 	}
 
+	scannedResources := make([]schema.GroupVersionResource, 0)
+	for _, gvr := range kubernetes.SortedGroupVersionResources(groupVersionResources) {
+		if !resourceWhitelist.Contains(gvr) {
+			log.Debugf("Not scanning resouce %s", strings.Join([]string{gvr.Group, gvr.Version, gvr.Resource}, "/"))
+			continue
+		}
+		scannedResources = append(scannedResources, gvr)
+	}
+	return scannedResources, namespacedResources, report, nil
+}
+
+// filterNamespaces returns the sorted names of the namespaces included by the namespace flags
+// and the excluded namespaces together with the rule excluding them
+func filterNamespaces(kubernetesAPI *kubernetes.API) ([]string, map[string]string, error) {
+	namespaceFilter, err := loadNamespaceFilter()
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Debug("Get excluded namespaces list...")
+	included, excluded, err := kubernetesAPI.FilterNamespaces(namespaceFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Debug("Getting excluded namespaces list done.")
+	names := make([]string, 0, len(excluded))
+	for name, rule := range excluded {
+		log.Debugf("Namespace %s excluded by %s", name, rule)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Infof("Excluded namespaces: %v", names)
+	return included, excluded, nil
+}
+
+// objectFilter returns a filter dropping all objects living in the excluded namespaces,
+// the excluded namespaces themselves and all objects excluded by the object selectors
+func objectFilter(excludedNamespaces map[string]string) (func(i *unstructured.Unstructured) bool, error) {
+	objectLabelFilter, err := loadLabelFilter(objectSelectorFlag, objectExcludeSelectorFlag)
+	if err != nil {
+		return nil, err
+	}
+	return func(i *unstructured.Unstructured) bool {
+		namespace := i.GetNamespace()
+		if i.GetKind() == "Namespace" && i.GroupVersionKind().Group == "" {
+			namespace = i.GetName()
+		}
+		if _, ok := excludedNamespaces[namespace]; ok {
+			return false
+		}
+		return objectLabelFilter.Matches(i.GetLabels())
+	}, nil
+}
+
+// clusterInfo returns the version and the API groups of the API server. Failures are logged and leave the fields empty,
+// because the information is not essential for the LDIF.
+func clusterInfo(kubernetesAPI *kubernetes.API) mapper.ClusterInfo {
+	var info mapper.ClusterInfo
+	serverVersion, err := kubernetesAPI.ServerVersion()
+	if err != nil {
+		log.Warningf("Failed to get server version: %s", err)
+	}
+	info.ServerVersion = serverVersion
+	apiGroups, err := kubernetesAPI.APIGroups()
+	if err != nil {
+		log.Warningf("Failed to get API groups: %s", err)
+	}
+	info.APIGroups = apiGroups
+	log.Debugf("Kubernetes server version: %s", info.ServerVersion)
+	return info
+}
+
+// copyReport returns a new report containing the failures of the given report
+func copyReport(r *kubernetes.RunReport) *kubernetes.RunReport {
+	c := kubernetes.NewRunReport()
+	c.ScannedResources = r.ScannedResources
+	c.Failures = append(c.Failures, r.Failures...)
+	return c
+}
+
+// buildLdif maps the cluster nodes and the collected resources into an LDIF.
+// Every listed page is mapped and written to the spool right away, so only small indexes of the objects are held
+// in memory. The content of the LDIF is read from the spool and can only be used until the spool is closed.
+// An error is returned if the failed resources violate the error policy.
+func buildLdif(kubernetesAPI *kubernetes.API, collect func(handle kubernetes.PageHandler) []kubernetes.CollectResult, spool *storage.Spool, runReport *kubernetes.RunReport, errorPolicy kubernetes.ErrorPolicy) (mapper.LDIF, error) {
 	log.Debug("Listing nodes...")
 	nodes, err := kubernetesAPI.Nodes()
 	if err != nil {
-		log.Fatal(err)
+		return mapper.LDIF{}, err
 	}
 	log.Debug("Listing nodes done.")
+	nodeLabels, err := loadNodeLabels()
+	if err != nil {
+		return mapper.LDIF{}, err
+	}
 
-	log.Debug("Map nodes to Kubernetes object")
-	clusterKubernetesObject, err := mapper.MapNodes(
-		viper.GetString("clustername"),
-		nodes,
-	)
+	redactionRules, err := loadRedactionRules()
 	if err != nil {
-		log.Fatal(err)
+		return mapper.LDIF{}, err
+	}
+	redactor := redact.NewRedactor(redactionRules)
+	projection, err := loadProjection()
+	if err != nil {
+		return mapper.LDIF{}, err
+	}
+	registry := mapper.Registry{}
+	if viper.GetBool(typedMappersFlag) {
+		registry = mapper.NewRegistry()
 	}
 
-	kubernetesObjects := make([]mapper.KubernetesObject, 0)
-	kubernetesObjects = append(kubernetesObjects, *clusterKubernetesObject)
-
-	resourceGroupWhitelist := map[string]map[string]interface{}{
-		"": map[string]interface{}{
-			"serviceaccounts":        struct{}{},
-			"services":               struct{}{},
-			"nodes":                  struct{}{},
-			"pods":                   struct{}{},
-			"namespaces":             struct{}{},
-			"configmaps":             struct{}{},
-			"persistentvolumes":      struct{}{},
-			"persistentvolumeclaims": struct{}{},
-			"replicationcontrollers": struct{}{},
-		},
-		"apps": map[string]interface{}{
-			"deployments":  struct{}{},
-			"statefulsets": struct{}{},
-			"daemonsets":   struct{}{},
-			"replicasets":  struct{}{},
-		},
-		"apiextensions.k8s.io": map[string]interface{}{
-			"customresourcedefinitions": struct{}{},
-		},
-		"rbac.authorization.k8s.io": map[string]interface{}{
-			"clusterrolebindings": struct{}{},
-			"rolebindings":        struct{}{},
-			"clusterroles":        struct{}{},
-			"roles":               struct{}{},
-		},
-		"networking.k8s.io": map[string]interface{}{
-			"ingresses":       struct{}{},
-			"networkpolicies": struct{}{},
-		},
-		"autoscaling": map[string]interface{}{
-			"horizontalpodautoscalers": struct{}{},
-		},
-		"policy": map[string]interface{}{
-			"podsecuritypolicies": struct{}{},
-		},
-		"storage.k8s.io": map[string]interface{}{
-			"storageclasses": struct{}{},
-		},
-		"batch": map[string]interface{}{
-			"cronjobs": struct{}{},
-			"jobs":     struct{}{},
-		},
+	ownershipMapping, err := loadOwnershipMapping()
+	if err != nil {
+		return mapper.LDIF{}, err
 	}
 
-	for gvr := range groupVersionResources {
-		if _, ok := resourceGroupWhitelist[gvr.Group][gvr.Resource]; !ok {
-			log.Debugf("Not scanning resouce %s", strings.Join([]string{gvr.Group, gvr.Version, gvr.Resource}, "/"))
+	owners := mapper.NewOwnerGraph()
+	ownership := mapper.NewOwnershipResolver(ownershipMapping, owners)
+	relationBuilder := mapper.NewRelationBuilder()
+	podResources := mapper.NewPodResources()
+	imageInventory := mapper.NewImageInventory(owners)
+	namespaceAggregator := mapper.NewNamespaceAggregator()
+	var spoolErr error
+	results := collect(func(gvr schema.GroupVersionResource, items []unstructured.Unstructured) {
+		if spoolErr != nil {
+			return
+		}
+		for n := range items {
+			i := &items[n]
+			// the indexes are built before the object is redacted and projected
+			owners.Add(i)
+			ownership.Add(i)
+			relationBuilder.Add(i)
+			err := podResources.Add(i)
+			if err != nil {
+				log.Warningf("Failed to collect resources of %s %s/%s: %s", i.GetKind(), i.GetNamespace(), i.GetName(), err)
+			}
+			redactor.Redact(i.GetKind(), i.Object)
+			err = imageInventory.Add(i)
+			if err != nil {
+				log.Warningf("Failed to collect images of %s %s/%s: %s", i.GetKind(), i.GetNamespace(), i.GetName(), err)
+			}
+			err = namespaceAggregator.Add(i)
+			if err != nil {
+				log.Warningf("Failed to aggregate %s %s/%s: %s", i.GetKind(), i.GetNamespace(), i.GetName(), err)
+			}
+			nko, err := registry.Map(i, projection)
+			if err != nil {
+				log.Warningf("Failed to map %s %s/%s, falling back to the raw object: %s", i.GetKind(), i.GetNamespace(), i.GetName(), err)
+				nko = mapper.MapObject(i, projection)
+			}
+			spoolErr = spool.Write(gvr.String(), storage.NewSpooledObject(i, nko))
+			if spoolErr != nil {
+				return
+			}
+		}
+	})
+	if spoolErr != nil {
+		return mapper.LDIF{}, spoolErr
+	}
+	for _, result := range results {
+		runReport.AddResult(result)
+		if result.Err != nil {
+			log.Warningf("Failed to list %s: %s", result.GVR.String(), result.Err)
+			// the pages listed before the failure are omitted like the instances of resources that failed entirely
+			err = spool.Drop(result.GVR.String())
+			if err != nil {
+				return mapper.LDIF{}, err
+			}
 			continue
 		}
-		instances, err := dynClient.Resource(gvr).List(metav1.ListOptions{})
-		if err != nil {
-			log.Panic(err)
+		log.Debugf("Listed %d instances of %s in %s", result.Count, result.GVR.String(), result.Duration)
+	}
+
+	log.Debug("Map nodes to Kubernetes object")
+	clusterKubernetesObject := mapper.MapNodes(
+		viper.GetString("clustername"),
+		nodes,
+		nodeLabels,
+		podResources,
+		clusterInfo(kubernetesAPI),
+	)
+	relations := relationBuilder.Relations()
+
+	// controllers and ownership are resolved once all objects were listed, as the controllers of an object
+	// may be listed after the object
+	collapseOwned := viper.GetBool(collapseOwnedFlag)
+	collapsed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			continue
 		}
-		for _, i := range instances.Items {
-			if _, ok := blacklistedNamespaces[i.GetNamespace()]; ok {
-				continue
+		err = spool.Each(result.GVR.String(), func(o storage.SpooledObject) error {
+			source := o.Source()
+			if collapseOwned && owners.Collapsible(source) {
+				collapsed++
+				return nil
 			}
-			nko := mapper.KubernetesObject{
-				Type: i.GetKind(),
-				ID:   string(i.GetUID()),
-				Data: i.Object,
+			nko := o.Object
+			if controller := owners.TopLevelController(o.UID); controller != nil {
+				mapper.SetController(&nko, controller)
 			}
-			kubernetesObjects = append(kubernetesObjects, nko)
+			mapper.SetOwnership(&nko, ownership.Resolve(source))
+			return spool.Write(resolvedSection, storage.SpooledObject{Object: nko})
+		})
+		if err != nil {
+			return mapper.LDIF{}, err
 		}
+		err = spool.Drop(result.GVR.String())
+		if err != nil {
+			return mapper.LDIF{}, err
+		}
+	}
+	if collapseOwned {
+		log.Debugf("Collapsed %d pods and replicasets into their owning workloads", collapsed)
+	}
+
+	kubernetesObjects := []mapper.Objects{
+		mapper.ObjectList{*clusterKubernetesObject},
+		spool.Objects(resolvedSection),
+	}
+	if viper.GetBool(imageInventoryFlag) {
+		images := imageInventory.Objects()
+		log.Debugf("Collected %d distinct images", len(images))
+		kubernetesObjects = append(kubernetesObjects, mapper.ObjectList(images))
+	}
+	if viper.GetBool(namespaceAggregatesFlag) {
+		kubernetesObjects = append(kubernetesObjects, mapper.ObjectList(namespaceAggregator.Objects(viper.GetString(clusterNameFlag), ownership)))
+	}
+	if viper.GetBool(relationsFlag) {
+		log.Debugf("Found %d relations", len(relations))
+		kubernetesObjects = append(kubernetesObjects, mapper.ObjectList(relations))
+	}
+
+	report, err := json.Marshal(runReport)
+	if err != nil {
+		return mapper.LDIF{}, err
+	}
+	log.Infof("Run report: %s", report)
+	redactionReport, err := json.Marshal(redactor.Report())
+	if err != nil {
+		return mapper.LDIF{}, err
+	}
+	log.Infof("Redaction report: %s", redactionReport)
+	err = errorPolicy.Check(runReport)
+	if err != nil {
+		return mapper.LDIF{}, err
 	}
 
 	customFields := mapper.CustomFields{
@@ -206,7 +526,7 @@ func main() {
 		BuildVersion:      version.VERSION,
 	}
 
-	ldif := mapper.LDIF{
+	return mapper.LDIF{
 		ConnectorID:         lxConnectorID,
 		ConnectorType:       lxConnectorType,
 		ConnectorVersion:    viper.GetString(connectorVersionFlag),
@@ -216,57 +536,144 @@ func main() {
 		LxWorkspace:         viper.GetString(lxWorkspaceFlag),
 		Description:         "Map Kubernetes objects to LeanIX Fact Sheets",
 		CustomFields:        customFields,
-		Content:             kubernetesObjects,
-	}
+		Content:             mapper.Concat(kubernetesObjects...),
+	}, nil
+}
 
-	log.Debug("Marshal ldif")
-	ldifByte, err := storage.Marshal(ldif)
+// closeSpool removes the spooled objects and logs failures to clean up
+func closeSpool(spool *storage.Spool) {
+	err := spool.Close()
 	if err != nil {
-		log.Fatal(err)
+		log.Warningf("Failed to remove the spooled objects: %s", err)
 	}
+}
 
-	log.Infof("Upload %s to %s", storage.LdifFileName, viper.GetString("storage-backend"))
-	azureOpts := storage.AzureBlobOpts{
-		AccountName: viper.GetString(azureAccountNameFlag),
-		AccountKey:  viper.GetString(azureAccountKeyFlag),
-		Container:   viper.GetString(azureContainerFlag),
+// syncLdif uploads the LDIF. In delta sync mode only the objects changed since the previous run are uploaded,
+// see delta.Syncer for the details.
+func syncLdif(uploader storage.Backend, ldif mapper.LDIF, runReport *kubernetes.RunReport) error {
+	if viper.GetString(syncModeFlag) != deltaSyncMode {
+		return syncFullLdif(uploader, ldif)
 	}
-	localFileOpts := storage.LocalFileOpts{
-		Path: viper.GetString(localFilePathFlag),
+	syncer := delta.Syncer{
+		Backend: uploader,
+		Upload: func(ldif mapper.LDIF, name string) error {
+			return uploadLdif(uploader, ldif, name)
+		},
+		FullSyncInterval: viper.GetDuration(fullSyncIntervalFlag),
+		MarkRemoved:      viper.GetBool(markRemovedFlag),
+	}
+	result, err := syncer.Sync(ldif, len(runReport.Failures))
+	if result.StateErr != nil {
+		log.Warningf("Ignored unreadable state of the previous run: %s", result.StateErr)
+	}
+	switch {
+	case result.Full:
+		log.Infof("Uploaded all objects: %s", result.Reason)
+	case result.Skipped:
+		log.Info("No changes since previous run, skipped upload")
+	default:
+		log.Infof("Changes since previous run: %d added, %d changed, %d removed", len(result.Changes.Added), len(result.Changes.Changed), len(result.Changes.Removed))
+	}
+	return err
+}
+
+// syncFullLdif uploads the whole LDIF. The upload is skipped if the hash of the LDIF equals the hash stored
+// by the previous run.
+func syncFullLdif(uploader storage.Backend, ldif mapper.LDIF) error {
+	if !viper.GetBool(skipUnchangedFlag) {
+		return uploadLdif(uploader, ldif, storage.LdifFileName)
 	}
-	uploader, err := storage.NewBackend(viper.GetString("storage-backend"), &azureOpts, &localFileOpts)
+	hash, err := storage.Hash(ldif)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	err = uploader.UploadLdif(ldifByte)
+	previousHash, err := uploader.DownloadLdifHash()
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if hash == previousHash {
+		log.Infof("LDIF unchanged since previous run (sha256 %s), skipping upload", hash)
+		return nil
 	}
+	log.Debugf("LDIF hash changed from '%s' to '%s'", previousHash, hash)
+	err = uploadLdif(uploader, ldif, storage.LdifFileName)
+	if err != nil {
+		return err
+	}
+	return uploader.UploadLdifHash(hash)
+}
+
+// uploadLdif streams the LDIF to the file with the given name in the storage backend and, if enabled, to the
+// Integration API and starts a run.
+// If anything fails on the way all writers are aborted, so neither a truncated LDIF replaces the previous one
+// in the storage backend nor a truncated LDIF is uploaded to the Integration API.
+func uploadLdif(uploader storage.Backend, ldif mapper.LDIF, name string) error {
+	var accessToken string
+	var syncRun leanix.SyncRunResponse
+	var ldifWriters []storage.LdifWriter
 	if viper.GetBool(integrationAPIFlag) == true {
-		log.Infof("========================Test run ================================="))
+		log.Infof("========================Test run =================================")
 		log.Infof("FQDN: %s , APIKEY %s", viper.GetString(integrationAPIFqdnFlag), viper.GetString(integrationAPITokenFlag))
-		accessToken, err := leanix.Authenticate(viper.GetString(integrationAPIFqdnFlag), viper.GetString(integrationAPITokenFlag))
+		var err error
+		accessToken, err = leanix.Authenticate(viper.GetString(integrationAPIFqdnFlag), viper.GetString(integrationAPITokenFlag))
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		log.Info("Integration API authentication successful.")
-		syncRun, err := leanix.Upload(viper.GetString(integrationAPIFqdnFlag), accessToken, ldifByte)
+		integrationAPIWriter := storage.NewStreamWriter(func(r io.Reader) error {
+			var err error
+			syncRun, err = leanix.UploadStream(viper.GetString(integrationAPIFqdnFlag), accessToken, r)
+			return err
+		})
+		ldifWriters = append(ldifWriters, integrationAPIWriter)
+	}
+
+	log.Infof("Upload %s to %s", name, viper.GetString(storageBackendFlag))
+	ldifWriter, err := uploader.LdifWriter(name)
+	if err != nil {
+		abortLdifWriters(ldifWriters, err)
+		return err
+	}
+	ldifWriters = append([]storage.LdifWriter{ldifWriter}, ldifWriters...)
+
+	log.Debug("Encode ldif")
+	writers := make([]io.Writer, len(ldifWriters))
+	for i, w := range ldifWriters {
+		writers[i] = w
+	}
+	err = storage.EncodeLdif(io.MultiWriter(writers...), ldif)
+	if err != nil {
+		abortLdifWriters(ldifWriters, err)
+		return err
+	}
+	for i, w := range ldifWriters {
+		err = w.Close()
 		if err != nil {
-			log.Fatal(err)
+			// the failed writer cleans up itself, the remaining ones are aborted
+			abortLdifWriters(ldifWriters[i+1:], err)
+			return err
 		}
+	}
+
+	if viper.GetBool(integrationAPIFlag) == true {
 		log.Infof("LDIF successfully uploaded to Integration API. id: %s", syncRun.ID)
 		runStatus, err := leanix.StartRun(viper.GetString(integrationAPIFqdnFlag), accessToken, syncRun.ID)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 		log.Infof("Integration API run successfully started. status: %d", runStatus)
 	}
-	log.Debug("-----------End-----------")
-	err = uploader.UploadLog(debugLogBuffer.Bytes())
-	if err != nil {
-		log.Fatal(err)
+	return nil
+}
+
+// abortLdifWriters aborts all writers with err and logs failures to clean up
+func abortLdifWriters(writers []storage.LdifWriter, err error) {
+	for _, w := range writers {
+		abortErr := w.Abort(err)
+		if abortErr != nil {
+			log.Warningf("Failed to discard the incomplete LDIF: %s", abortErr)
+		}
 	}
-	log.Info("-----------End-----------")
 }
 
 func ServerPreferredListableResources(d discovery.DiscoveryInterface) ([]*metav1.APIResourceList, error) {
@@ -290,7 +697,37 @@ func parseFlags() error {
 	flag.Bool(integrationAPIFlag, false, "enable Integration API usage")
 	flag.String(integrationAPIFqdnFlag, "app.leanix.net", "LeanIX Instance FQDN")
 	flag.String(integrationAPITokenFlag, "", "LeanIX API token")
-	flag.StringSlice(blacklistNamespacesFlag, []string{""}, "list of namespaces that are not scanned")
+	flag.StringSlice(blacklistNamespacesFlag, []string{""}, "list of namespaces that are not scanned in the format [exact:|glob:|regex:]pattern")
+	flag.StringSlice(whitelistNamespacesFlag, []string{}, "list of namespaces that are scanned in the format [exact:|glob:|regex:]pattern, all namespaces are scanned if empty")
+	flag.StringSlice(resourceWhitelistFlag, kubernetes.DefaultResourceWhitelist, "list of resources that are scanned in the format [group/[version/]]resource, wildcards are supported")
+	flag.String(resourceWhitelistFileFlag, "", "file containing the resource whitelist with one entry per line, overrides the resource-whitelist flag")
+	flag.Int64(pageSizeFlag, kubernetes.DefaultPageSize, "maximum number of objects requested per list call, 0 disables chunking")
+	flag.Int(workersFlag, kubernetes.DefaultWorkers, "number of resource types listed concurrently")
+	flag.String(errorPolicyFlag, kubernetes.FailFast, fmt.Sprintf("behaviour when resources fail to be discovered or listed (%s, %s, %s), %s requires the processing mode partial", kubernetes.FailFast, kubernetes.FailOnThreshold, kubernetes.BestEffort, kubernetes.BestEffort))
+	flag.Int(errorThresholdFlag, 0, fmt.Sprintf("number of failed resources tolerated by the %s error policy", kubernetes.FailOnThreshold))
+	flag.String(syncModeFlag, fullSyncMode, fmt.Sprintf("upload all objects on every run or only the objects changed since the previous run (%s, %s)", fullSyncMode, deltaSyncMode))
+	flag.Duration(fullSyncIntervalFlag, 24*time.Hour, "interval all objects are uploaded in delta sync mode")
+	flag.Bool(markRemovedFlag, false, "upload removed objects with the data field deleted set to true in delta sync mode")
+	flag.Bool(skipUnchangedFlag, false, "skip the upload in full sync mode if the LDIF content did not change since the previous run")
+	flag.StringSlice(redactionRulesFlag, []string{}, "list of additional fields that are redacted in the format kind:path, e.g. Deployment:metadata.annotations.*")
+	flag.StringSlice(projectionIncludeFlag, []string{}, "list of fields that are kept in the format kind:path, kinds without entries keep all fields")
+	flag.StringSlice(projectionExcludeFlag, mapper.DefaultProjectionExclude, "list of fields that are removed in the format kind:path")
+	flag.Bool(keepRawObjectsFlag, false, "add the unprojected objects to the LDIF for debugging")
+	flag.Bool(typedMappersFlag, false, "map workloads into a normalized schema instead of the raw object")
+	flag.Bool(imageInventoryFlag, true, "add an object for every distinct container image running in the cluster")
+	flag.Bool(collapseOwnedFlag, false, "omit pods and replicasets owned by a scanned workload")
+	flag.Bool(namespaceAggregatesFlag, true, "add an aggregate object for every scanned namespace")
+	flag.StringSlice(applicationSourcesFlag, mapper.DefaultApplicationSources, "list of labels and annotations holding the LeanIX application in the format label:key or annotation:key")
+	flag.StringSlice(ownerSourcesFlag, []string{}, "list of labels and annotations holding the owner in the format label:key or annotation:key")
+	flag.String(namespaceSelectorFlag, "", "label selector of the scanned namespaces, e.g. team in (a,b)")
+	flag.String(namespaceExcludeSelectorFlag, kubernetes.DefaultExcludeSelector, "label selector of the namespaces that are not scanned")
+	flag.String(objectSelectorFlag, "", "label selector of the scanned objects")
+	flag.String(objectExcludeSelectorFlag, kubernetes.DefaultExcludeSelector, "label selector of the objects that are not scanned")
+	flag.StringSlice(nodeLabelsFlag, []string{}, fmt.Sprintf("list of custom node labels the topology, instance type and node pool are read from in the format field=key (%s, %s, %s, %s)", mapper.ZoneLabelField, mapper.RegionLabelField, mapper.InstanceTypeLabelField, mapper.NodePoolLabelField))
+	flag.Bool(relationsFlag, true, "add relation objects between services, ingresses, workloads, volumes and service accounts")
+	flag.String(modeFlag, oneShotMode, fmt.Sprintf("run once and exit or keep watching the cluster (%s, %s)", oneShotMode, watchMode))
+	flag.Duration(watchIntervalFlag, 10*time.Minute, "interval the LDIF is emitted in watch mode")
+	flag.Duration(watchDebounceFlag, 30*time.Second, "quiet period after changes before the LDIF is emitted in watch mode, 0 disables emitting on changes")
 	flag.String(lxWorkspaceFlag, "", "name of the LeanIX workspace the data is sent to")
 	flag.Bool(localFlag, false, "use local kubeconfig from home folder")
 	flag.Parse()
@@ -312,6 +749,47 @@ func parseFlags() error {
 	if viper.GetString(lxWorkspaceFlag) == "" {
 		return fmt.Errorf("%s flag must be set", lxWorkspaceFlag)
 	}
+	if viper.GetInt64(pageSizeFlag) < 0 {
+		return fmt.Errorf("%s flag must not be negative", pageSizeFlag)
+	}
+	if viper.GetInt(workersFlag) < 1 {
+		return fmt.Errorf("%s flag must be at least 1", workersFlag)
+	}
+	switch viper.GetString(syncModeFlag) {
+	case fullSyncMode, deltaSyncMode:
+	default:
+		return fmt.Errorf("unsupported %s %s (%s, %s)", syncModeFlag, viper.GetString(syncModeFlag), fullSyncMode, deltaSyncMode)
+	}
+	if _, err := loadRedactionRules(); err != nil {
+		return err
+	}
+	if _, err := loadProjection(); err != nil {
+		return err
+	}
+	if _, err := loadOwnershipMapping(); err != nil {
+		return err
+	}
+	if _, err := loadNamespaceFilter(); err != nil {
+		return err
+	}
+	if _, err := loadNodeLabels(); err != nil {
+		return err
+	}
+	if _, err := loadLabelFilter(objectSelectorFlag, objectExcludeSelectorFlag); err != nil {
+		return err
+	}
+	switch viper.GetString(modeFlag) {
+	case oneShotMode:
+	case watchMode:
+		if viper.GetDuration(watchIntervalFlag) <= 0 {
+			return fmt.Errorf("%s flag must be positive", watchIntervalFlag)
+		}
+		if viper.GetDuration(watchDebounceFlag) < 0 {
+			return fmt.Errorf("%s flag must not be negative", watchDebounceFlag)
+		}
+	default:
+		return fmt.Errorf("unsupported %s %s (%s, %s)", modeFlag, viper.GetString(modeFlag), oneShotMode, watchMode)
+	}
 	if viper.GetString(storageBackendFlag) == "azureblob" {
 		if viper.GetString(azureAccountNameFlag) == "" {
 			return fmt.Errorf("%s flag must be set", azureAccountNameFlag)
@@ -331,8 +809,124 @@ func parseFlags() error {
 	return nil
 }
 
+// loadResourceWhitelist loads the resource whitelist from the whitelist file if given, otherwise from the whitelist flag
+func loadResourceWhitelist() (kubernetes.ResourceWhitelist, error) {
+	if filename := viper.GetString(resourceWhitelistFileFlag); filename != "" {
+		log.Infof("Load resource whitelist from %s", filename)
+		return kubernetes.LoadResourceWhitelist(filename)
+	}
+	return kubernetes.ParseResourceWhitelist(viper.GetStringSlice(resourceWhitelistFlag))
+}
+
+// loadRedactionRules returns the built-in redaction rules followed by the rules of the redaction rules flag
+func loadRedactionRules() ([]redact.Rule, error) {
+	rules, err := redact.ParseRules(viper.GetStringSlice(redactionRulesFlag))
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]redact.Rule{}, redact.DefaultRules...), rules...), nil
+}
+
+// loadProjection returns the projection configured by the projection flags
+func loadProjection() (mapper.Projection, error) {
+	include, err := fieldpath.ParseKindPaths(viper.GetStringSlice(projectionIncludeFlag))
+	if err != nil {
+		return mapper.Projection{}, fmt.Errorf("invalid %s: %s", projectionIncludeFlag, err)
+	}
+	exclude, err := fieldpath.ParseKindPaths(viper.GetStringSlice(projectionExcludeFlag))
+	if err != nil {
+		return mapper.Projection{}, fmt.Errorf("invalid %s: %s", projectionExcludeFlag, err)
+	}
+	return mapper.Projection{
+		Include: include,
+		Exclude: exclude,
+		KeepRaw: viper.GetBool(keepRawObjectsFlag),
+	}, nil
+}
+
+// loadOwnershipMapping returns the ownership sources configured by the application and owner sources flags
+func loadOwnershipMapping() (mapper.OwnershipMapping, error) {
+	application, err := mapper.ParseOwnershipSources(viper.GetStringSlice(applicationSourcesFlag))
+	if err != nil {
+		return mapper.OwnershipMapping{}, fmt.Errorf("invalid %s: %s", applicationSourcesFlag, err)
+	}
+	owner, err := mapper.ParseOwnershipSources(viper.GetStringSlice(ownerSourcesFlag))
+	if err != nil {
+		return mapper.OwnershipMapping{}, fmt.Errorf("invalid %s: %s", ownerSourcesFlag, err)
+	}
+	return mapper.OwnershipMapping{
+		Application: application,
+		Owner:       owner,
+	}, nil
+}
+
+// loadNamespaceFilter returns the namespace filter configured by the namespace flags
+func loadNamespaceFilter() (kubernetes.NamespaceFilter, error) {
+	blacklist, err := kubernetes.ParseNamespacePatterns(viper.GetStringSlice(blacklistNamespacesFlag))
+	if err != nil {
+		return kubernetes.NamespaceFilter{}, fmt.Errorf("invalid %s: %s", blacklistNamespacesFlag, err)
+	}
+	whitelist, err := kubernetes.ParseNamespacePatterns(viper.GetStringSlice(whitelistNamespacesFlag))
+	if err != nil {
+		return kubernetes.NamespaceFilter{}, fmt.Errorf("invalid %s: %s", whitelistNamespacesFlag, err)
+	}
+	labelFilter, err := loadLabelFilter(namespaceSelectorFlag, namespaceExcludeSelectorFlag)
+	if err != nil {
+		return kubernetes.NamespaceFilter{}, err
+	}
+	return kubernetes.NamespaceFilter{
+		Blacklist: blacklist,
+		Whitelist: whitelist,
+		Labels:    labelFilter,
+	}, nil
+}
+
+// loadNodeLabels returns the node labels configured by the node labels flag followed by the default node labels
+func loadNodeLabels() (mapper.NodeLabels, error) {
+	nodeLabels, err := mapper.ParseNodeLabels(viper.GetStringSlice(nodeLabelsFlag))
+	if err != nil {
+		return mapper.NodeLabels{}, fmt.Errorf("invalid %s: %s", nodeLabelsFlag, err)
+	}
+	return nodeLabels, nil
+}
+
+// loadLabelFilter returns the label filter configured by the given include and exclude selector flags
+func loadLabelFilter(includeFlag string, excludeFlag string) (kubernetes.LabelFilter, error) {
+	filter, err := kubernetes.NewLabelFilter(viper.GetString(includeFlag), viper.GetString(excludeFlag))
+	if err != nil {
+		return kubernetes.LabelFilter{}, fmt.Errorf("invalid %s or %s: %s", includeFlag, excludeFlag, err)
+	}
+	return filter, nil
+}
+
+// logBuffer is a bytes.Buffer safe for concurrent use by the logger and the log upload
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Bytes returns a copy of the buffered log
+func (b *logBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+// Reset discards the buffered log
+func (b *logBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
 // InitLogger initialise the logger for stdout and log file
-func initLogger() (logging.LeveledBackend, *bytes.Buffer) {
+func initLogger() (logging.LeveledBackend, *logBuffer) {
 	format := logging.MustStringFormatter(`%{time} ▶ [%{level:.4s}] %{message}`)
 	logging.SetFormatter(format)
 
@@ -341,7 +935,7 @@ func initLogger() (logging.LeveledBackend, *bytes.Buffer) {
 	stdoutLeveled := logging.AddModuleLevel(stdout)
 
 	// file logging backend
-	var mem bytes.Buffer
+	var mem logBuffer
 	fileLogger := logging.NewLogBackend(&mem, "", 0)
 	logging.SetBackend(fileLogger, stdoutLeveled)
 	return stdoutLeveled, &mem
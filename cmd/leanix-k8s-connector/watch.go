@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/kubernetes"
+	"github.com/leanix/leanix-k8s-connector/pkg/storage"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// watchSyncTimeout is the time to wait for the informer caches to be filled before the first LDIF is emitted
+const watchSyncTimeout = 2 * time.Minute
+
+// watch keeps the scanned resources in informer caches and emits the LDIF on the configured interval
+// and after changes in the cluster until the process is terminated.
+func watch(kubernetesAPI *kubernetes.API, dynClient dynamic.Interface, scannedResources []schema.GroupVersionResource, discoveryReport *kubernetes.RunReport, errorPolicy kubernetes.ErrorPolicy, uploader storage.Backend, debugLogBuffer *logBuffer) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+
+	log.Infof("Start watching %d resources", len(scannedResources))
+	objectLabelFilter, err := loadLabelFilter(objectSelectorFlag, objectExcludeSelectorFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	watcher := kubernetes.NewResourceWatcher(dynClient, scannedResources, 0, objectLabelFilter.ListSelector())
+	watcher.Start(stopCh, watchSyncTimeout)
+
+	emit := func() {
+		_, excludedNamespaces, err := filterNamespaces(kubernetesAPI)
+		if err != nil {
+			log.Errorf("Failed to get excluded namespaces: %s", err)
+			return
+		}
+		filter, err := objectFilter(excludedNamespaces)
+		if err != nil {
+			log.Errorf("Failed to create object filter: %s", err)
+			return
+		}
+		watcher.Filter = filter
+		runReport := copyReport(discoveryReport)
+		spool, err := storage.NewSpool("")
+		if err != nil {
+			log.Errorf("Failed to create spool: %s", err)
+			return
+		}
+		defer closeSpool(spool)
+		ldif, err := buildLdif(kubernetesAPI, watcher.Collect, spool, runReport, errorPolicy)
+		if err != nil {
+			log.Errorf("Skipping LDIF: %s", err)
+		} else if err = syncLdif(uploader, ldif, runReport); err != nil {
+			log.Errorf("Failed to upload LDIF: %s", err)
+		}
+		err = uploader.UploadLog(debugLogBuffer.Bytes())
+		if err != nil {
+			log.Errorf("Failed to upload log: %s", err)
+			return
+		}
+		// the log file only covers the latest emitted LDIF to keep the memory consumption bounded
+		debugLogBuffer.Reset()
+	}
+	emit()
+
+	debounce := viper.GetDuration(watchDebounceFlag)
+	ticker := time.NewTicker(viper.GetDuration(watchIntervalFlag))
+	defer ticker.Stop()
+	// the debounce timer is only armed after changes
+	debounceTimer := time.NewTimer(time.Hour)
+	debounceTimer.Stop()
+	for {
+		select {
+		case <-watcher.Changes():
+			if debounce == 0 {
+				continue
+			}
+			// restart the quiet period on every change
+			debounceTimer.Stop()
+			select {
+			case <-debounceTimer.C:
+			default:
+			}
+			debounceTimer.Reset(debounce)
+		case <-debounceTimer.C:
+			log.Debug("Emit LDIF after changes")
+			emit()
+		case <-ticker.C:
+			log.Debug("Emit LDIF on interval")
+			emit()
+		case s := <-signals:
+			log.Infof("Received %s, stop watching", s)
+			err := uploader.UploadLog(debugLogBuffer.Bytes())
+			if err != nil {
+				log.Error(err)
+			}
+			return
+		}
+	}
+}
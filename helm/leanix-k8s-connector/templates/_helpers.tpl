@@ -55,3 +55,184 @@ Ensure Integration API schedule lowest value is every hour
 {{- printf "%s" "0 */1 * * *" -}}
 {{- end -}}
 {{- end -}}
+
+{{/*
+Pod spec of the connector shared by the CronJob and the Deployment
+*/}}
+{{- define "leanix-k8s-connector.podSpec" -}}
+{{- if .Values.rbac }}
+serviceAccountName: leanix-k8s-connector
+{{- end }}
+containers:
+- name: connector
+  securityContext:
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+    runAsUser: {{ .Values.securityContext.userId | default 65534 }}
+    runAsGroup: {{ .Values.securityContext.groupId | default 65534 }}
+    allowPrivilegeEscalation: false
+  image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+  env:
+  - name: CLUSTERNAME
+    value: "{{ .Values.args.clustername }}"
+  - name: LX_WORKSPACE
+    value: "{{ .Values.args.lxWorkspace }}"
+  {{- if .Values.args.verbose }}
+  - name: VERBOSE
+    value: "true"
+  {{- end }}
+  - name: STORAGE_BACKEND
+    value: "{{ .Values.args.storageBackend }}"
+  {{- if eq .Values.args.storageBackend "file" }}
+  - name: LOCAL_FILE_PATH
+    value: "{{ .Values.args.file.localFilePath }}"
+  {{- else if eq .Values.args.storageBackend "azureblob" }}
+  - name: AZURE_ACCOUNT_NAME
+    valueFrom:
+      secretKeyRef:
+        name: "{{ .Values.args.azureblob.secretName }}"
+        key: azurestorageaccountname
+  - name: AZURE_ACCOUNT_KEY
+    valueFrom:
+      secretKeyRef:
+        name: "{{ .Values.args.azureblob.secretName }}"
+        key: azurestorageaccountkey
+  - name: AZURE_CONTAINER
+    value: "{{ .Values.args.azureblob.container }}"
+  {{- end }}
+  - name: CONNECTOR_ID
+    value: "{{ .Values.args.connectorID | default uuidv4 }}"
+  - name: CONNECTOR_VERSION
+    value: "{{ .Values.args.connectorVersion }}"
+  - name: PROCESSING_MODE
+    value: "{{ .Values.args.processingMode }}"
+  - name: BLACKLIST_NAMESPACES
+    value: "{{ .Values.args.blacklistNamespaces | join ", " }}"
+  {{- if .Values.args.whitelistNamespaces }}
+  - name: WHITELIST_NAMESPACES
+    value: "{{ .Values.args.whitelistNamespaces | join ", " }}"
+  {{- end }}
+  {{- if .Values.args.namespaceSelector }}
+  - name: NAMESPACE_SELECTOR
+    value: "{{ .Values.args.namespaceSelector }}"
+  {{- end }}
+  {{- if .Values.args.namespaceExcludeSelector }}
+  - name: NAMESPACE_EXCLUDE_SELECTOR
+    value: "{{ .Values.args.namespaceExcludeSelector }}"
+  {{- end }}
+  {{- if .Values.args.objectSelector }}
+  - name: OBJECT_SELECTOR
+    value: "{{ .Values.args.objectSelector }}"
+  {{- end }}
+  {{- if .Values.args.objectExcludeSelector }}
+  - name: OBJECT_EXCLUDE_SELECTOR
+    value: "{{ .Values.args.objectExcludeSelector }}"
+  {{- end }}
+  - name: MODE
+    value: "{{ .Values.mode }}"
+  {{- if eq .Values.mode "watch" }}
+  - name: WATCH_INTERVAL
+    value: "{{ .Values.watch.interval }}"
+  - name: WATCH_DEBOUNCE
+    value: "{{ .Values.watch.debounce }}"
+  {{- end }}
+  - name: SYNC_MODE
+    value: "{{ .Values.args.syncMode }}"
+  {{- if eq .Values.args.syncMode "delta" }}
+  - name: FULL_SYNC_INTERVAL
+    value: "{{ .Values.args.fullSyncInterval }}"
+  - name: MARK_REMOVED
+    value: "{{ .Values.args.markRemoved }}"
+  {{- else }}
+  - name: SKIP_UNCHANGED
+    value: "{{ .Values.args.skipUnchanged }}"
+  {{- end }}
+  - name: PAGE_SIZE
+    value: "{{ .Values.args.pageSize }}"
+  - name: WORKERS
+    value: "{{ .Values.args.workers }}"
+  - name: ERROR_POLICY
+    value: "{{ .Values.args.errorPolicy }}"
+  - name: ERROR_THRESHOLD
+    value: "{{ .Values.args.errorThreshold }}"
+  {{- if .Values.args.resourceWhitelist }}
+  - name: RESOURCE_WHITELIST
+    value: "{{ .Values.args.resourceWhitelist | join "," }}"
+  {{- end }}
+  {{- if .Values.args.redactionRules }}
+  - name: REDACTION_RULES
+    value: "{{ .Values.args.redactionRules | join "," }}"
+  {{- end }}
+  {{- if .Values.args.projection.include }}
+  - name: PROJECTION_INCLUDE
+    value: "{{ .Values.args.projection.include | join "," }}"
+  {{- end }}
+  {{- if .Values.args.projection.exclude }}
+  - name: PROJECTION_EXCLUDE
+    value: "{{ .Values.args.projection.exclude | join "," }}"
+  {{- end }}
+  - name: TYPED_MAPPERS
+    value: "{{ .Values.args.typedMappers }}"
+  - name: IMAGE_INVENTORY
+    value: "{{ .Values.args.imageInventory }}"
+  - name: COLLAPSE_OWNED
+    value: "{{ .Values.args.collapseOwned }}"
+  - name: NAMESPACE_AGGREGATES
+    value: "{{ .Values.args.namespaceAggregates }}"
+  - name: RELATIONS
+    value: "{{ .Values.args.relations }}"
+  {{- if .Values.args.nodeLabels }}
+  - name: NODE_LABELS
+    value: "{{ .Values.args.nodeLabels | join "," }}"
+  {{- end }}
+  {{- if .Values.args.applicationSources }}
+  - name: APPLICATION_SOURCES
+    value: "{{ .Values.args.applicationSources | join "," }}"
+  {{- end }}
+  {{- if .Values.args.ownerSources }}
+  - name: OWNER_SOURCES
+    value: "{{ .Values.args.ownerSources | join "," }}"
+  {{- end }}
+  {{- if .Values.args.projection.keepRawObjects }}
+  - name: KEEP_RAW_OBJECTS
+    value: "true"
+  {{- end }}
+  {{- if .Values.integrationApi.enabled }}
+  - name: INTEGRATION_API_ENABLED
+    value: "true"
+  - name: INTEGRATION_API_FQDN
+    value: "{{ .Values.integrationApi.fqdn }}"
+  - name: INTEGRATION_API_TOKEN
+    valueFrom:
+      secretKeyRef:
+        name: "{{ .Values.integrationApi.secretName }}"
+        key: token
+  {{- end }}
+  {{- range $key, $val := .Values.args.additionalEnv }}
+  - name: {{ $key }}
+    value: {{ $val | quote }}
+  {{- end }}
+  resources:
+    requests:
+      cpu: {{ .Values.resources.requests.cpu }}
+      memory: {{ .Values.resources.requests.memory }}
+    limits:
+      cpu: {{ .Values.resources.limits.cpu }}
+      memory: {{ .Values.resources.limits.memory }}
+  volumeMounts:
+  # the mapped objects are spooled to temporary files, the root filesystem is read-only
+  - mountPath: /tmp
+    name: tmp
+{{- if eq .Values.args.storageBackend "file" }}
+  - mountPath: "{{ .Values.args.file.localFilePath }}"
+    name: volume
+{{- end }}
+volumes:
+  - name: tmp
+    emptyDir: {}
+{{- if eq .Values.args.storageBackend "file" }}
+  - name: volume
+    persistentVolumeClaim:
+      claimName: "{{ .Values.args.file.claimName }}"
+{{- end }}
+{{- end -}}
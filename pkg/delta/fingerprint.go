@@ -0,0 +1,146 @@
+package delta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+)
+
+// PartialProcessingMode is the processing mode of delta LDIFs
+const PartialProcessingMode string = "partial"
+
+// ObjectFingerprint identifies the content of a single object
+type ObjectFingerprint struct {
+	Type string `json:"type"`
+	Hash string `json:"hash"`
+}
+
+// Fingerprint identifies the content of all objects of a run. It is persisted as state for the next run.
+type Fingerprint struct {
+	LastFullSync time.Time                    `json:"lastFullSync"`
+	Objects      map[string]ObjectFingerprint `json:"objects"`
+}
+
+// Changes lists the ids of the objects that changed compared to a previous run
+type Changes struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// NewFingerprint hashes the content of the given objects
+func NewFingerprint(objects mapper.Objects) (*Fingerprint, error) {
+	f := &Fingerprint{
+		Objects: make(map[string]ObjectFingerprint),
+	}
+	err := objects.Each(func(o mapper.KubernetesObject) error {
+		hash, err := hashObject(o)
+		if err != nil {
+			return err
+		}
+		f.Objects[o.ID] = ObjectFingerprint{
+			Type: o.Type,
+			Hash: hash,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Unmarshal reads a fingerprint persisted with Marshal
+func Unmarshal(state []byte) (*Fingerprint, error) {
+	f := &Fingerprint{}
+	err := json.Unmarshal(state, f)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Marshal serializes the fingerprint to be persisted as state
+func (f *Fingerprint) Marshal() ([]byte, error) {
+	return json.Marshal(f)
+}
+
+// Compare returns the objects added, changed and removed in the current fingerprint compared to the previous one
+func Compare(previous *Fingerprint, current *Fingerprint) Changes {
+	c := Changes{
+		Added:   make([]string, 0),
+		Changed: make([]string, 0),
+		Removed: make([]string, 0),
+	}
+	for id, o := range current.Objects {
+		p, ok := previous.Objects[id]
+		if !ok {
+			c.Added = append(c.Added, id)
+		} else if p.Hash != o.Hash {
+			c.Changed = append(c.Changed, id)
+		}
+	}
+	for id := range previous.Objects {
+		if _, ok := current.Objects[id]; !ok {
+			c.Removed = append(c.Removed, id)
+		}
+	}
+	sort.Strings(c.Added)
+	sort.Strings(c.Changed)
+	sort.Strings(c.Removed)
+	return c
+}
+
+// Empty returns true if no object was added, changed or removed
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Changed) == 0 && len(c.Removed) == 0
+}
+
+// Ldif returns a copy of the LDIF with the partial processing mode, that only contains the added and changed objects.
+// Removed objects are included with their type and id and the data field deleted set to true.
+// The content is filtered while it is iterated, so it is not held in memory.
+func Ldif(ldif mapper.LDIF, changes Changes, previous *Fingerprint) mapper.LDIF {
+	modified := make(map[string]bool, len(changes.Added)+len(changes.Changed))
+	for _, id := range changes.Added {
+		modified[id] = true
+	}
+	for _, id := range changes.Changed {
+		modified[id] = true
+	}
+	content := ldif.Content
+	changed := mapper.ObjectsFunc(func(fn func(o mapper.KubernetesObject) error) error {
+		return content.Each(func(o mapper.KubernetesObject) error {
+			if !modified[o.ID] {
+				return nil
+			}
+			return fn(o)
+		})
+	})
+	removed := make(mapper.ObjectList, 0, len(changes.Removed))
+	for _, id := range changes.Removed {
+		removed = append(removed, mapper.KubernetesObject{
+			Type: previous.Objects[id].Type,
+			ID:   id,
+			Data: map[string]interface{}{
+				"deleted": true,
+			},
+		})
+	}
+	ldif.ProcessingMode = PartialProcessingMode
+	ldif.Content = mapper.Concat(changed, removed)
+	return ldif
+}
+
+func hashObject(o mapper.KubernetesObject) (string, error) {
+	// encoding/json sorts map keys, so equal objects always produce equal hashes
+	b, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
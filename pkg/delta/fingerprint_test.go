@@ -0,0 +1,104 @@
+package delta
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/stretchr/testify/assert"
+)
+
+func pod(id string, image string) mapper.KubernetesObject {
+	return mapper.KubernetesObject{
+		Type: "Pod",
+		ID:   id,
+		Data: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"image": image,
+			},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	previous, err := NewFingerprint(mapper.ObjectList{
+		pod("1", "nginx:1.19"),
+		pod("2", "nginx:1.19"),
+		pod("3", "nginx:1.19"),
+	})
+	assert.NoError(t, err)
+	current, err := NewFingerprint(mapper.ObjectList{
+		pod("1", "nginx:1.19"),
+		pod("2", "nginx:1.21"),
+		pod("4", "nginx:1.21"),
+	})
+	assert.NoError(t, err)
+
+	changes := Compare(previous, current)
+
+	assert.Equal(t, []string{"4"}, changes.Added)
+	assert.Equal(t, []string{"2"}, changes.Changed)
+	assert.Equal(t, []string{"3"}, changes.Removed)
+	assert.False(t, changes.Empty())
+}
+
+func TestCompare_unchanged(t *testing.T) {
+	previous, err := NewFingerprint(mapper.ObjectList{pod("1", "nginx:1.19")})
+	assert.NoError(t, err)
+	current, err := NewFingerprint(mapper.ObjectList{pod("1", "nginx:1.19")})
+	assert.NoError(t, err)
+
+	changes := Compare(previous, current)
+
+	assert.True(t, changes.Empty())
+}
+
+func TestFingerprintMarshal(t *testing.T) {
+	f, err := NewFingerprint(mapper.ObjectList{pod("1", "nginx:1.19")})
+	assert.NoError(t, err)
+	f.LastFullSync = time.Date(2021, 8, 4, 10, 0, 0, 0, time.UTC)
+
+	state, err := f.Marshal()
+	assert.NoError(t, err)
+	restored, err := Unmarshal(state)
+	assert.NoError(t, err)
+
+	assert.Equal(t, f, restored)
+}
+
+func TestLdif(t *testing.T) {
+	previous, err := NewFingerprint(mapper.ObjectList{pod("1", "nginx:1.19"), pod("2", "nginx:1.19")})
+	assert.NoError(t, err)
+	ldif := mapper.LDIF{
+		ConnectorID:    "Kubernetes",
+		ProcessingMode: "full",
+		Content:        mapper.ObjectList{pod("1", "nginx:1.21"), pod("3", "nginx:1.21")},
+	}
+	current, err := NewFingerprint(ldif.Content)
+	assert.NoError(t, err)
+
+	d := Ldif(ldif, Compare(previous, current), previous)
+
+	assert.Equal(t, "Kubernetes", d.ConnectorID)
+	assert.Equal(t, PartialProcessingMode, d.ProcessingMode)
+	assert.Equal(t, mapper.ObjectList{
+		pod("1", "nginx:1.21"),
+		pod("3", "nginx:1.21"),
+		mapper.KubernetesObject{
+			Type: "Pod",
+			ID:   "2",
+			Data: map[string]interface{}{"deleted": true},
+		},
+	}, objects(t, d.Content))
+	assert.Equal(t, "full", ldif.ProcessingMode)
+}
+
+// objects iterates the objects into a list
+func objects(t *testing.T, content mapper.Objects) mapper.ObjectList {
+	list := make(mapper.ObjectList, 0)
+	assert.NoError(t, content.Each(func(o mapper.KubernetesObject) error {
+		list = append(list, o)
+		return nil
+	}))
+	return list
+}
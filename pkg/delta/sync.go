@@ -0,0 +1,105 @@
+package delta
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/leanix/leanix-k8s-connector/pkg/storage"
+)
+
+// Syncer uploads only the objects changed since the previous run. The fingerprint of the previous run is
+// persisted as state in the storage backend.
+type Syncer struct {
+	Backend storage.Backend
+	// Upload uploads the LDIF to the file with the given name in the storage backend and to the Integration API
+	Upload func(ldif mapper.LDIF, name string) error
+	// FullSyncInterval is the interval all objects are uploaded
+	FullSyncInterval time.Duration
+	// MarkRemoved includes the removed objects with the data field deleted set to true. Otherwise removed objects
+	// are only removed from LeanIX by the next full sync.
+	MarkRemoved bool
+	// Now returns the current time. time.Now is used if it is nil.
+	Now func() time.Time
+}
+
+// SyncResult describes what was uploaded by Sync
+type SyncResult struct {
+	// Full is true if all objects were uploaded. Reason describes why.
+	Full   bool
+	Reason string
+	// Changes are the changes uploaded in the partial processing mode
+	Changes Changes
+	// Skipped is true if nothing changed and the upload was skipped
+	Skipped bool
+	// StateErr is the error of an unreadable state of the previous run, that was ignored
+	StateErr error
+}
+
+// Sync uploads all objects of the LDIF if there is no state of a previous run, the full sync interval passed or
+// resources failed, whose objects are missing in the LDIF and would be uploaded as removed. Otherwise only the
+// changes are uploaded and the upload is skipped if nothing changed. The state is updated after a successful upload.
+func (s *Syncer) Sync(ldif mapper.LDIF, failures int) (SyncResult, error) {
+	var result SyncResult
+	current, err := NewFingerprint(ldif.Content)
+	if err != nil {
+		return result, err
+	}
+	state, err := s.Backend.DownloadState()
+	if err != nil {
+		return result, err
+	}
+	var previous *Fingerprint
+	if state != nil {
+		previous, err = Unmarshal(state)
+		if err != nil {
+			result.StateErr = err
+			previous = nil
+		}
+	}
+
+	now := s.now()
+	switch {
+	case failures > 0:
+		result.Reason = fmt.Sprintf("%d resources failed to be discovered or listed", failures)
+	case previous == nil:
+		result.Reason = "no state of a previous run"
+	case now.Sub(previous.LastFullSync) >= s.FullSyncInterval:
+		result.Reason = "full sync interval passed"
+	}
+	if result.Reason != "" {
+		result.Full = true
+		err = s.Upload(ldif, storage.LdifFileName)
+		if err != nil {
+			return result, err
+		}
+		current.LastFullSync = now
+	} else {
+		result.Changes = Compare(previous, current)
+		if !s.MarkRemoved {
+			result.Changes.Removed = make([]string, 0)
+		}
+		if result.Changes.Empty() {
+			result.Skipped = true
+			return result, nil
+		}
+		err = s.Upload(Ldif(ldif, result.Changes, previous), storage.DeltaLdifFileName)
+		if err != nil {
+			return result, err
+		}
+		current.LastFullSync = previous.LastFullSync
+	}
+
+	state, err = current.Marshal()
+	if err != nil {
+		return result, err
+	}
+	return result, s.Backend.UploadState(state)
+}
+
+func (s *Syncer) now() time.Time {
+	if s.Now == nil {
+		return time.Now().UTC()
+	}
+	return s.Now()
+}
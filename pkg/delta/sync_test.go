@@ -0,0 +1,190 @@
+package delta
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/leanix/leanix-k8s-connector/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// stateBackend keeps the state in memory, the LDIF is uploaded by the upload function of the Syncer
+type stateBackend struct {
+	storage.Backend
+	state []byte
+}
+
+func (b *stateBackend) DownloadState() ([]byte, error) {
+	return b.state, nil
+}
+
+func (b *stateBackend) UploadState(state []byte) error {
+	b.state = state
+	return nil
+}
+
+// upload records an uploaded LDIF with its content iterated into a list
+type upload struct {
+	name string
+	ldif mapper.LDIF
+}
+
+func TestSyncerSync(t *testing.T) {
+	now := time.Date(2021, 8, 4, 10, 0, 0, 0, time.UTC)
+	lastFullSync := now.Add(-time.Hour)
+	state := func(lastFullSync time.Time, objects ...mapper.KubernetesObject) []byte {
+		f, err := NewFingerprint(mapper.ObjectList(objects))
+		assert.NoError(t, err)
+		f.LastFullSync = lastFullSync
+		s, err := f.Marshal()
+		assert.NoError(t, err)
+		return s
+	}
+	removed := mapper.KubernetesObject{Type: "Pod", ID: "2", Data: map[string]interface{}{"deleted": true}}
+
+	tests := map[string]struct {
+		state        []byte
+		content      []mapper.KubernetesObject
+		failures     int
+		markRemoved  bool
+		uploadErr    error
+		stateErr     bool
+		expected     SyncResult
+		uploads      []upload
+		lastFullSync time.Time
+		stateKept    bool
+	}{
+		"no previous state": {
+			content:      []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			expected:     SyncResult{Full: true, Reason: "no state of a previous run"},
+			uploads:      []upload{{storage.LdifFileName, ldif("full", pod("1", "nginx:1.19"))}},
+			lastFullSync: now,
+		},
+		"unreadable state": {
+			state:        []byte("{"),
+			content:      []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			stateErr:     true,
+			expected:     SyncResult{Full: true, Reason: "no state of a previous run"},
+			uploads:      []upload{{storage.LdifFileName, ldif("full", pod("1", "nginx:1.19"))}},
+			lastFullSync: now,
+		},
+		"full sync interval passed": {
+			state:        state(now.Add(-24*time.Hour), pod("1", "nginx:1.19")),
+			content:      []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			expected:     SyncResult{Full: true, Reason: "full sync interval passed"},
+			uploads:      []upload{{storage.LdifFileName, ldif("full", pod("1", "nginx:1.19"))}},
+			lastFullSync: now,
+		},
+		"failed resources": {
+			state:        state(lastFullSync, pod("1", "nginx:1.19"), pod("2", "nginx:1.19")),
+			content:      []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			failures:     2,
+			expected:     SyncResult{Full: true, Reason: "2 resources failed to be discovered or listed"},
+			uploads:      []upload{{storage.LdifFileName, ldif("full", pod("1", "nginx:1.19"))}},
+			lastFullSync: now,
+		},
+		"changes": {
+			state:   state(lastFullSync, pod("1", "nginx:1.19"), pod("2", "nginx:1.19")),
+			content: []mapper.KubernetesObject{pod("1", "nginx:1.21"), pod("3", "nginx:1.21")},
+			expected: SyncResult{Changes: Changes{
+				Added:   []string{"3"},
+				Changed: []string{"1"},
+				Removed: []string{},
+			}},
+			uploads:      []upload{{storage.DeltaLdifFileName, ldif(PartialProcessingMode, pod("1", "nginx:1.21"), pod("3", "nginx:1.21"))}},
+			lastFullSync: lastFullSync,
+		},
+		"changes with removed objects marked": {
+			state:       state(lastFullSync, pod("1", "nginx:1.19"), pod("2", "nginx:1.19")),
+			content:     []mapper.KubernetesObject{pod("1", "nginx:1.21")},
+			markRemoved: true,
+			expected: SyncResult{Changes: Changes{
+				Added:   []string{},
+				Changed: []string{"1"},
+				Removed: []string{"2"},
+			}},
+			uploads:      []upload{{storage.DeltaLdifFileName, ldif(PartialProcessingMode, pod("1", "nginx:1.21"), removed)}},
+			lastFullSync: lastFullSync,
+		},
+		"no changes": {
+			state:   state(lastFullSync, pod("1", "nginx:1.19")),
+			content: []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			expected: SyncResult{Skipped: true, Changes: Changes{
+				Added:   []string{},
+				Changed: []string{},
+				Removed: []string{},
+			}},
+			stateKept: true,
+		},
+		"only removed objects not marked": {
+			state:   state(lastFullSync, pod("1", "nginx:1.19"), pod("2", "nginx:1.19")),
+			content: []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			expected: SyncResult{Skipped: true, Changes: Changes{
+				Added:   []string{},
+				Changed: []string{},
+				Removed: []string{},
+			}},
+			stateKept: true,
+		},
+		"failed upload": {
+			content:   []mapper.KubernetesObject{pod("1", "nginx:1.19")},
+			uploadErr: errors.New("forbidden"),
+			expected:  SyncResult{Full: true, Reason: "no state of a previous run"},
+			uploads:   []upload{{storage.LdifFileName, ldif("full", pod("1", "nginx:1.19"))}},
+			stateKept: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := &stateBackend{state: tt.state}
+			uploads := make([]upload, 0)
+			syncer := Syncer{
+				Backend: backend,
+				Upload: func(ldif mapper.LDIF, name string) error {
+					ldif.Content = objects(t, ldif.Content)
+					uploads = append(uploads, upload{name, ldif})
+					return tt.uploadErr
+				},
+				FullSyncInterval: 24 * time.Hour,
+				MarkRemoved:      tt.markRemoved,
+				Now: func() time.Time {
+					return now
+				},
+			}
+
+			result, err := syncer.Sync(ldif("full", tt.content...), tt.failures)
+
+			assert.Equal(t, tt.uploadErr, err)
+			assert.Equal(t, tt.stateErr, result.StateErr != nil)
+			result.StateErr = nil
+			assert.Equal(t, tt.expected, result)
+			if tt.uploads == nil {
+				tt.uploads = []upload{}
+			}
+			assert.Equal(t, tt.uploads, uploads)
+			if tt.stateKept {
+				assert.Equal(t, tt.state, backend.state)
+				return
+			}
+			f, err := Unmarshal(backend.state)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.lastFullSync, f.LastFullSync)
+			current, err := NewFingerprint(mapper.ObjectList(tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, current.Objects, f.Objects)
+		})
+	}
+}
+
+func ldif(processingMode string, content ...mapper.KubernetesObject) mapper.LDIF {
+	if content == nil {
+		content = []mapper.KubernetesObject{}
+	}
+	return mapper.LDIF{
+		ConnectorID:    "Kubernetes",
+		ProcessingMode: processingMode,
+		Content:        mapper.ObjectList(content),
+	}
+}
@@ -0,0 +1,66 @@
+package fieldpath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnyKind selects objects of every kind
+const AnyKind string = "*"
+
+// KindPath is a path that applies to objects of a kind
+type KindPath struct {
+	Kind string
+	Path Path
+}
+
+// ParseKindPath parses a path in the format kind:path, e.g. Deployment:spec.template.metadata.annotations.*
+// The kind * selects objects of every kind.
+func ParseKindPath(s string) (KindPath, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return KindPath{}, fmt.Errorf("invalid entry %q: expected kind:path", s)
+	}
+	p, err := Parse(parts[1])
+	if err != nil {
+		return KindPath{}, fmt.Errorf("invalid entry %q: %s", s, err)
+	}
+	return KindPath{Kind: parts[0], Path: p}, nil
+}
+
+// ParseKindPaths parses a list of kind paths. Every item may contain multiple comma separated kind paths.
+func ParseKindPaths(entries []string) ([]KindPath, error) {
+	paths := make([]KindPath, 0)
+	for _, item := range entries {
+		for _, s := range strings.Split(item, ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			p, err := ParseKindPath(s)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// MustParseKindPath is like ParseKindPath but panics if the entry cannot be parsed
+func MustParseKindPath(s string) KindPath {
+	p, err := ParseKindPath(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Matches returns true if the path applies to objects of the kind
+func (p KindPath) Matches(kind string) bool {
+	return p.Kind == AnyKind || p.Kind == kind
+}
+
+// String returns the path in the format kind:path
+func (p KindPath) String() string {
+	return p.Kind + ":" + p.Path.String()
+}
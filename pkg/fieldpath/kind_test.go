@@ -0,0 +1,27 @@
+package fieldpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKindPaths(t *testing.T) {
+	paths, err := ParseKindPaths([]string{"Pod:metadata.labels, *:metadata.managedFields", ""})
+
+	assert.NoError(t, err)
+	assert.Len(t, paths, 2)
+	assert.Equal(t, "Pod:metadata.labels", paths[0].String())
+	assert.True(t, paths[0].Matches("Pod"))
+	assert.False(t, paths[0].Matches("Service"))
+	assert.True(t, paths[1].Matches("Service"))
+}
+
+func TestParseKindPath_invalid(t *testing.T) {
+	for _, input := range []string{"metadata.name", ":metadata.name", "Pod:", "Pod:spec["} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseKindPath(input)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package fieldpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type segmentType int
+
+const (
+	fieldSegment segmentType = iota
+	indexSegment
+	wildcardSegment
+	descentSegment
+)
+
+// segment is a single step of a path
+type segment struct {
+	typ   segmentType
+	field string
+	index int
+}
+
+// Path is a parsed JSONPath-style expression selecting fields of an object.
+// It supports child fields (.name or ['name']), array indices ([0]), wildcards (.* or [*])
+// matching all map values and array elements, and recursive descent (..name).
+type Path struct {
+	expression string
+	segments   []segment
+}
+
+// Parse parses a path like metadata.annotations['example.com/key'], spec.containers[*].env[*].value or $..env[*].value
+func Parse(expression string) (Path, error) {
+	p := Path{expression: expression}
+	s := strings.TrimPrefix(strings.TrimSpace(expression), "$")
+	if s == "" {
+		return p, fmt.Errorf("invalid path %q: path must not be empty", expression)
+	}
+	// a leading field name may omit the dot
+	if s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			p.segments = append(p.segments, segment{typ: descentSegment})
+			s = s[1:]
+		case s[0] == '.':
+			name := s[1:]
+			end := strings.IndexAny(name, ".[")
+			if end >= 0 {
+				name = name[:end]
+			}
+			if name == "" {
+				return p, fmt.Errorf("invalid path %q: empty field name", expression)
+			}
+			if name == "*" {
+				p.segments = append(p.segments, segment{typ: wildcardSegment})
+			} else {
+				p.segments = append(p.segments, segment{typ: fieldSegment, field: name})
+			}
+			s = s[1+len(name):]
+		case s[0] == '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return p, fmt.Errorf("invalid path %q: missing ]", expression)
+			}
+			seg, err := parseBracket(s[1:end])
+			if err != nil {
+				return p, fmt.Errorf("invalid path %q: %s", expression, err)
+			}
+			p.segments = append(p.segments, seg)
+			s = s[end+1:]
+		default:
+			return p, fmt.Errorf("invalid path %q: unexpected %q", expression, s[0])
+		}
+	}
+	if p.segments[len(p.segments)-1].typ == descentSegment {
+		return p, fmt.Errorf("invalid path %q: path must not end with ..", expression)
+	}
+	return p, nil
+}
+
+func parseBracket(s string) (segment, error) {
+	if s == "*" {
+		return segment{typ: wildcardSegment}, nil
+	}
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return segment{typ: fieldSegment, field: s[1 : len(s)-1]}, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return segment{}, fmt.Errorf("expected quoted field name, index or * in brackets, got %q", s)
+	}
+	return segment{typ: indexSegment, index: i}, nil
+}
+
+// String returns the expression the path was parsed from
+func (p Path) String() string {
+	return p.expression
+}
+
+// Walk calls fn for every value selected by the path in obj. The container holding the value is passed
+// together with the key or index, so fn can modify or delete the value. It returns the number of calls.
+func (p Path) Walk(obj interface{}, fn func(container interface{}, key string, index int)) int {
+	return walk(obj, p.segments, fn)
+}
+
+// Delete removes the fields selected by the path from obj and returns the number of removed fields.
+// Array elements cannot be removed and are skipped.
+func (p Path) Delete(obj interface{}) int {
+	count := 0
+	p.Walk(obj, func(container interface{}, key string, index int) {
+		if m, ok := container.(map[string]interface{}); ok {
+			delete(m, key)
+			count++
+		}
+	})
+	return count
+}
+
+func walk(node interface{}, segments []segment, fn func(container interface{}, key string, index int)) int {
+	seg := segments[0]
+	last := len(segments) == 1
+	count := 0
+	visit := func(container interface{}, key string, index int, child interface{}) {
+		if last {
+			fn(container, key, index)
+			count++
+		} else {
+			count += walk(child, segments[1:], fn)
+		}
+	}
+	switch seg.typ {
+	case descentSegment:
+		count += walk(node, segments[1:], fn)
+		for _, child := range children(node) {
+			count += walk(child, segments, fn)
+		}
+	case fieldSegment:
+		if m, ok := node.(map[string]interface{}); ok {
+			if child, ok := m[seg.field]; ok {
+				visit(m, seg.field, 0, child)
+			}
+		}
+	case indexSegment:
+		if a, ok := node.([]interface{}); ok && seg.index < len(a) {
+			visit(a, "", seg.index, a[seg.index])
+		}
+	case wildcardSegment:
+		switch n := node.(type) {
+		case map[string]interface{}:
+			for k, child := range n {
+				visit(n, k, 0, child)
+			}
+		case []interface{}:
+			for i, child := range n {
+				visit(n, "", i, child)
+			}
+		}
+	}
+	return count
+}
+
+func children(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		c := make([]interface{}, 0, len(n))
+		for _, child := range n {
+			c = append(c, child)
+		}
+		return c
+	case []interface{}:
+		return n
+	}
+	return nil
+}
+
+// Project returns a copy of obj that only contains the fields selected by any of the paths.
+// Projected arrays keep their length, elements without selected fields are set to nil.
+func Project(obj map[string]interface{}, paths []Path) map[string]interface{} {
+	projected := make(map[string]interface{})
+	for _, p := range paths {
+		if v, ok := project(obj, p.segments); ok {
+			projected = merge(projected, v).(map[string]interface{})
+		}
+	}
+	return projected
+}
+
+func project(node interface{}, segments []segment) (interface{}, bool) {
+	if len(segments) == 0 {
+		return runtime.DeepCopyJSONValue(node), true
+	}
+	seg := segments[0]
+	switch seg.typ {
+	case descentSegment:
+		v, ok := project(node, segments[1:])
+		if c, childOk := projectChildren(node, segments); childOk {
+			if ok {
+				return merge(v, c), true
+			}
+			return c, true
+		}
+		return v, ok
+	case fieldSegment:
+		if m, ok := node.(map[string]interface{}); ok {
+			if child, ok := m[seg.field]; ok {
+				if v, ok := project(child, segments[1:]); ok {
+					return map[string]interface{}{seg.field: v}, true
+				}
+			}
+		}
+	case indexSegment:
+		if a, ok := node.([]interface{}); ok && seg.index < len(a) {
+			if v, ok := project(a[seg.index], segments[1:]); ok {
+				projected := make([]interface{}, len(a))
+				projected[seg.index] = v
+				return projected, true
+			}
+		}
+	case wildcardSegment:
+		return projectChildren(node, segments[1:])
+	}
+	return nil, false
+}
+
+// projectChildren applies the segments to all map values or array elements of node
+func projectChildren(node interface{}, segments []segment) (interface{}, bool) {
+	found := false
+	switch n := node.(type) {
+	case map[string]interface{}:
+		projected := make(map[string]interface{})
+		for k, child := range n {
+			if v, ok := project(child, segments); ok {
+				projected[k] = v
+				found = true
+			}
+		}
+		return projected, found
+	case []interface{}:
+		projected := make([]interface{}, len(n))
+		for i, child := range n {
+			if v, ok := project(child, segments); ok {
+				projected[i] = v
+				found = true
+			}
+		}
+		return projected, found
+	}
+	return nil, false
+}
+
+// merge merges src into dst. Maps are merged by key and arrays of equal length by index, otherwise src wins.
+func merge(dst interface{}, src interface{}) interface{} {
+	switch d := dst.(type) {
+	case map[string]interface{}:
+		if s, ok := src.(map[string]interface{}); ok {
+			for k, v := range s {
+				if existing, ok := d[k]; ok {
+					d[k] = merge(existing, v)
+				} else {
+					d[k] = v
+				}
+			}
+			return d
+		}
+	case []interface{}:
+		if s, ok := src.([]interface{}); ok && len(s) == len(d) {
+			for i, v := range s {
+				if d[i] == nil {
+					d[i] = v
+				} else if v != nil {
+					d[i] = merge(d[i], v)
+				}
+			}
+			return d
+		}
+	}
+	return src
+}
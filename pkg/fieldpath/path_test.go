@@ -0,0 +1,143 @@
+package fieldpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected []segment
+	}{
+		"fields": {
+			input:    "metadata.name",
+			expected: []segment{{typ: fieldSegment, field: "metadata"}, {typ: fieldSegment, field: "name"}},
+		},
+		"root": {
+			input:    "$.data.*",
+			expected: []segment{{typ: fieldSegment, field: "data"}, {typ: wildcardSegment}},
+		},
+		"quoted field": {
+			input:    "metadata.annotations['example.com/key']",
+			expected: []segment{{typ: fieldSegment, field: "metadata"}, {typ: fieldSegment, field: "annotations"}, {typ: fieldSegment, field: "example.com/key"}},
+		},
+		"index and wildcard": {
+			input:    "spec.containers[0].env[*]",
+			expected: []segment{{typ: fieldSegment, field: "spec"}, {typ: fieldSegment, field: "containers"}, {typ: indexSegment, index: 0}, {typ: fieldSegment, field: "env"}, {typ: wildcardSegment}},
+		},
+		"recursive descent": {
+			input:    "$..env[*].value",
+			expected: []segment{{typ: descentSegment}, {typ: fieldSegment, field: "env"}, {typ: wildcardSegment}, {typ: fieldSegment, field: "value"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, p.segments)
+			assert.Equal(t, tt.input, p.String())
+		})
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, input := range []string{"", "$", "metadata..", "spec.[", "spec[-1]", "spec[name]", "metadata.", "spec..."} {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testObject() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":          "nginx",
+			"labels":        map[string]interface{}{"app": "nginx"},
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "nginx", "image": "nginx:1.19"},
+				map[string]interface{}{"name": "sidecar", "image": "envoy:1.17"},
+			},
+		},
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := map[string]struct {
+		paths    []string
+		expected map[string]interface{}
+	}{
+		"fields": {
+			paths: []string{"metadata.name", "metadata.labels", "spec.replicas"},
+			expected: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "nginx", "labels": map[string]interface{}{"app": "nginx"}},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+			},
+		},
+		"wildcard": {
+			paths: []string{"spec.containers[*].image"},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"image": "nginx:1.19"},
+					map[string]interface{}{"image": "envoy:1.17"},
+				}},
+			},
+		},
+		"overlapping paths": {
+			paths: []string{"spec.containers[*].image", "spec.containers[1]"},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"image": "nginx:1.19"},
+					map[string]interface{}{"name": "sidecar", "image": "envoy:1.17"},
+				}},
+			},
+		},
+		"recursive descent": {
+			paths: []string{"..name"},
+			expected: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "nginx"},
+				"spec": map[string]interface{}{"containers": []interface{}{
+					map[string]interface{}{"name": "nginx"},
+					map[string]interface{}{"name": "sidecar"},
+				}},
+			},
+		},
+		"missing field": {
+			paths:    []string{"status.phase"},
+			expected: map[string]interface{}{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			paths := make([]Path, len(tt.paths))
+			for i, s := range tt.paths {
+				p, err := Parse(s)
+				assert.NoError(t, err)
+				paths[i] = p
+			}
+			obj := testObject()
+
+			projected := Project(obj, paths)
+
+			assert.Equal(t, tt.expected, projected)
+			assert.Equal(t, testObject(), obj)
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	p, err := Parse("metadata.managedFields")
+	assert.NoError(t, err)
+	obj := testObject()
+
+	count := p.Delete(obj)
+
+	assert.Equal(t, 1, count)
+	assert.NotContains(t, obj["metadata"], "managedFields")
+}
@@ -39,3 +39,27 @@ func Prefix(l []string, p string) []string {
 	}
 	return r
 }
+
+// ServerVersion returns the git version of the Kubernetes API server, e.g. v1.18.14
+func (k *API) ServerVersion() (string, error) {
+	info, err := k.Client.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return info.GitVersion, nil
+}
+
+// APIGroups returns the names of the API groups served by the cluster. The core group is omitted.
+func (k *API) APIGroups() ([]string, error) {
+	groupList, err := k.Client.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(groupList.Groups))
+	for _, g := range groupList.Groups {
+		if g.Name != "" {
+			groups = append(groups, g.Name)
+		}
+	}
+	return groups, nil
+}
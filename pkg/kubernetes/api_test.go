@@ -4,6 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestBlacklistFieldSelector(t *testing.T) {
@@ -22,3 +26,29 @@ func TestPrefix(t *testing.T) {
 
 	assert.Equal(t, []string{"new-foo", "new-bar"}, r)
 }
+
+func TestServerVersion(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.18.14-eks-7737de"}
+	k := API{Client: client}
+
+	v, err := k.ServerVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.18.14-eks-7737de", v)
+}
+
+func TestAPIGroups(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "apps/v1"},
+		{GroupVersion: "route.openshift.io/v1"},
+	}
+	k := API{Client: client}
+
+	groups, err := k.APIGroups()
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"apps", "route.openshift.io"}, groups)
+}
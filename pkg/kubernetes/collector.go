@@ -0,0 +1,183 @@
+package kubernetes
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultWorkers is the default number of resources listed concurrently
+const DefaultWorkers int = 4
+
+// ResourceCollector lists the instances of multiple resources concurrently
+type ResourceCollector struct {
+	Client   dynamic.Interface
+	PageSize int64
+	Workers  int
+	// LabelSelector restricts the listed instances to the instances with matching labels
+	LabelSelector string
+	// Scope restricts the listed namespaces. It only applies to the namespaced resources.
+	Scope NamespaceScope
+	// Namespaced holds the namespaced resources. Only namespaced resources are listed per namespace.
+	Namespaced map[schema.GroupVersionResource]bool
+	// Filter is called for every listed instance. Instances are dropped if it returns false.
+	Filter func(i *unstructured.Unstructured) bool
+	// StopOnError stops listing further resources once a resource failed to be listed
+	StopOnError bool
+}
+
+// PageHandler handles the listed instances of a resource page by page. The items are not used by the collector
+// after the handler returned, so the handler may modify them.
+type PageHandler func(gvr schema.GroupVersionResource, items []unstructured.Unstructured)
+
+// CollectResult describes the listing of a single resource. The listed instances are handed to the PageHandler.
+// The instances of a failed resource may have been handed to the PageHandler partially.
+type CollectResult struct {
+	GVR      schema.GroupVersionResource
+	Count    int
+	Duration time.Duration
+	Err      error
+}
+
+// Collect lists all given resources with at most Workers resources listed at the same time and hands every page
+// to handle. Only one page is handled at a time, so handle does not have to be safe for concurrent use.
+// The results are returned in the order of the given resources. With StopOnError resources that were not
+// listed yet when a resource failed are skipped and have no result.
+func (c *ResourceCollector) Collect(gvrs []schema.GroupVersionResource, handle PageHandler) []CollectResult {
+	var mu sync.Mutex
+	serialized := func(gvr schema.GroupVersionResource, items []unstructured.Unstructured) {
+		mu.Lock()
+		defer mu.Unlock()
+		handle(gvr, items)
+	}
+	results := make([]CollectResult, len(gvrs))
+	collected := make([]bool, len(gvrs))
+	var stopped int32
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if atomic.LoadInt32(&stopped) == 1 {
+					continue
+				}
+				results[i] = c.collect(gvrs[i], serialized)
+				collected[i] = true
+				if results[i].Err != nil && c.StopOnError {
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+		}()
+	}
+	for i := range gvrs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if atomic.LoadInt32(&stopped) == 0 {
+		return results
+	}
+	completed := make([]CollectResult, 0, len(results))
+	for i, r := range results {
+		if collected[i] {
+			completed = append(completed, r)
+		}
+	}
+	return completed
+}
+
+func (c *ResourceCollector) collect(gvr schema.GroupVersionResource, handle PageHandler) CollectResult {
+	start := time.Now()
+	result := CollectResult{
+		GVR: gvr,
+	}
+	add := func(items []unstructured.Unstructured) error {
+		filtered := items[:0]
+		for _, i := range items {
+			if c.Filter != nil && !c.Filter(&i) {
+				continue
+			}
+			filtered = append(filtered, i)
+		}
+		result.Count += len(filtered)
+		if len(filtered) > 0 {
+			handle(gvr, filtered)
+		}
+		return nil
+	}
+	opts := metav1.ListOptions{LabelSelector: c.LabelSelector}
+	if c.Scope.PerNamespace && c.Namespaced[gvr] {
+		for _, namespace := range c.Scope.Namespaces {
+			result.Err = ListPages(c.Client.Resource(gvr).Namespace(namespace), opts, c.PageSize, add)
+			if result.Err != nil {
+				break
+			}
+		}
+	} else {
+		if c.Namespaced[gvr] {
+			// the API server rejects the metadata.namespace field selector for cluster-scoped resources
+			opts.FieldSelector = c.Scope.FieldSelector
+		}
+		result.Err = ListPages(c.Client.Resource(gvr), opts, c.PageSize, add)
+		if result.Err != nil && opts.FieldSelector != "" && apierrors.IsBadRequest(result.Err) && result.Count == 0 {
+			// not every API supports the metadata.namespace field selector, e.g. some aggregated APIs.
+			// The field selector is rejected before any page is returned. The excluded namespaces are
+			// still dropped by the filter.
+			opts.FieldSelector = ""
+			result.Err = ListPages(c.Client.Resource(gvr), opts, c.PageSize, add)
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// SortedGroupVersionResources returns the given resources sorted by group, version and resource name
+func SortedGroupVersionResources(gvrs map[schema.GroupVersionResource]struct{}) []schema.GroupVersionResource {
+	sorted := make([]schema.GroupVersionResource, 0, len(gvrs))
+	for gvr := range gvrs {
+		sorted = append(sorted, gvr)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Group != sorted[j].Group {
+			return sorted[i].Group < sorted[j].Group
+		}
+		if sorted[i].Version != sorted[j].Version {
+			return sorted[i].Version < sorted[j].Version
+		}
+		return sorted[i].Resource < sorted[j].Resource
+	})
+	return sorted
+}
+
+// NamespacedResources returns the set of namespaced resources of the discovered resource lists
+func NamespacedResources(resourceLists []*metav1.APIResourceList) (map[schema.GroupVersionResource]bool, error) {
+	namespaced := make(map[schema.GroupVersionResource]bool)
+	for _, list := range resourceLists {
+		if list == nil {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list.APIResources {
+			if r.Namespaced {
+				namespaced[gv.WithResource(r.Name)] = true
+			}
+		}
+	}
+	return namespaced, nil
+}
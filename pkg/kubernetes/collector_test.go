@@ -0,0 +1,232 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newUnstructured(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+		},
+	}
+}
+
+// handledItems holds the items handed to the PageHandler by resource
+type handledItems map[schema.GroupVersionResource][]unstructured.Unstructured
+
+func (h handledItems) handle(gvr schema.GroupVersionResource, items []unstructured.Unstructured) {
+	h[gvr] = append(h[gvr], items...)
+}
+
+var (
+	podsResource        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	secretsResource     = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	deploymentsResource = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	namespacesResource  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+)
+
+func TestResourceCollectorCollect(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("v1", "Pod", "default", "nginx"),
+		newUnstructured("v1", "Pod", "kube-system", "kube-proxy"),
+		newUnstructured("apps/v1", "Deployment", "default", "nginx"),
+	)
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	collector := ResourceCollector{
+		Client:   client,
+		PageSize: 10,
+		Workers:  2,
+		Filter: func(i *unstructured.Unstructured) bool {
+			return i.GetNamespace() != "kube-system"
+		},
+	}
+	gvrs := []schema.GroupVersionResource{podsResource, secretsResource, deploymentsResource}
+	items := handledItems{}
+
+	results := collector.Collect(gvrs, items.handle)
+
+	assert.Len(t, results, 3)
+	for i, r := range results {
+		assert.Equal(t, gvrs[i], r.GVR)
+	}
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Count)
+	assert.Len(t, items[podsResource], 1)
+	assert.Equal(t, "nginx", items[podsResource][0].GetName())
+	assert.EqualError(t, results[1].Err, "forbidden")
+	assert.Empty(t, items[secretsResource])
+	assert.NoError(t, results[2].Err)
+	assert.Len(t, items[deploymentsResource], 1)
+	assert.Equal(t, "Deployment", items[deploymentsResource][0].GetKind())
+}
+
+func TestResourceCollectorCollect_labelSelector(t *testing.T) {
+	labeled := newUnstructured("v1", "Pod", "default", "nginx")
+	labeled.SetLabels(map[string]string{"team": "a"})
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		labeled,
+		newUnstructured("v1", "Pod", "default", "redis"),
+	)
+	collector := ResourceCollector{
+		Client:        client,
+		LabelSelector: "team=a",
+	}
+
+	items := handledItems{}
+
+	results := collector.Collect([]schema.GroupVersionResource{podsResource}, items.handle)
+
+	assert.NoError(t, results[0].Err)
+	assert.Len(t, items[podsResource], 1)
+	assert.Equal(t, "nginx", items[podsResource][0].GetName())
+}
+
+func TestResourceCollectorCollect_perNamespace(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("v1", "Pod", "default", "nginx"),
+		newUnstructured("v1", "Pod", "shop", "web"),
+		newUnstructured("v1", "Pod", "kube-system", "kube-proxy"),
+		newUnstructured("v1", "Namespace", "", "shop"),
+	)
+	namespaces := make([]string, 0)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		namespaces = append(namespaces, action.GetNamespace())
+		return false, nil, nil
+	})
+	collector := ResourceCollector{
+		Client: client,
+		Scope:  NamespaceScope{PerNamespace: true, Namespaces: []string{"default", "shop"}},
+		Namespaced: map[schema.GroupVersionResource]bool{
+			podsResource: true,
+		},
+	}
+
+	items := handledItems{}
+
+	results := collector.Collect([]schema.GroupVersionResource{podsResource, namespacesResource}, items.handle)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []string{"default", "shop"}, namespaces)
+	assert.Len(t, items[podsResource], 2)
+	assert.Equal(t, "nginx", items[podsResource][0].GetName())
+	assert.Equal(t, "web", items[podsResource][1].GetName())
+	assert.NoError(t, results[1].Err)
+	assert.Len(t, items[namespacesResource], 1)
+}
+
+func TestResourceCollectorCollect_fieldSelector(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("v1", "Pod", "default", "nginx"),
+	)
+	selectors := make([]string, 0)
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		selectors = append(selectors, action.(k8stesting.ListAction).GetListRestrictions().Fields.String())
+		return false, nil, nil
+	})
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.ListAction).GetListRestrictions().Fields.Empty() {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewBadRequest("field label not supported: metadata.namespace")
+	})
+	client.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.ListAction).GetListRestrictions().Fields.Empty() {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewBadRequest("field label not supported: metadata.namespace")
+	})
+	collector := ResourceCollector{
+		Client:     client,
+		Scope:      NamespaceScope{FieldSelector: "metadata.namespace!=kube-system"},
+		Namespaced: map[schema.GroupVersionResource]bool{podsResource: true, secretsResource: true},
+	}
+
+	items := handledItems{}
+
+	results := collector.Collect([]schema.GroupVersionResource{podsResource, secretsResource, namespacesResource}, items.handle)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []string{"metadata.namespace!=kube-system"}, selectors)
+	assert.Len(t, items[podsResource], 1)
+	assert.NoError(t, results[1].Err, "lists without field selector if the field selector is not supported")
+	assert.NoError(t, results[2].Err, "cluster-scoped resources are listed without field selector")
+}
+
+func TestResourceCollectorCollect_stopOnError(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("apps/v1", "Deployment", "default", "nginx"),
+	)
+	client.PrependReactor("list", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	listed := make([]string, 0)
+	client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		listed = append(listed, action.GetResource().Resource)
+		return false, nil, nil
+	})
+	collector := ResourceCollector{
+		Client:      client,
+		Workers:     1,
+		StopOnError: true,
+	}
+
+	results := collector.Collect([]schema.GroupVersionResource{podsResource, secretsResource, deploymentsResource}, handledItems{}.handle)
+
+	assert.Equal(t, []string{"pods", "secrets"}, listed)
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+}
+
+func TestSortedGroupVersionResources(t *testing.T) {
+	gvrs := map[schema.GroupVersionResource]struct{}{
+		deploymentsResource: struct{}{},
+		secretsResource:     struct{}{},
+		podsResource:        struct{}{},
+	}
+
+	sorted := SortedGroupVersionResources(gvrs)
+
+	assert.Equal(t, []schema.GroupVersionResource{podsResource, secretsResource, deploymentsResource}, sorted)
+}
+
+func TestNamespacedResources(t *testing.T) {
+	lists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true},
+				{Name: "namespaces", Namespaced: false},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true},
+			},
+		},
+	}
+
+	namespaced, err := NamespacedResources(lists)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[schema.GroupVersionResource]bool{podsResource: true, deploymentsResource: true}, namespaced)
+}
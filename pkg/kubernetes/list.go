@@ -0,0 +1,33 @@
+package kubernetes
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultPageSize is the default number of items requested per list call
+const DefaultPageSize int64 = 500
+
+// ListPages lists all instances of a resource in chunks of pageSize items using the Limit and Continue
+// list options and hands every page to fn. Only a single page is held in memory at once.
+// A pageSize of 0 disables chunking and lists all instances with a single call.
+func ListPages(client dynamic.ResourceInterface, opts metav1.ListOptions, pageSize int64, fn func(items []unstructured.Unstructured) error) error {
+	opts.Limit = pageSize
+	opts.Continue = ""
+	for {
+		page, err := client.List(opts)
+		if err != nil {
+			return err
+		}
+		err = fn(page.Items)
+		if err != nil {
+			return err
+		}
+		next := page.GetContinue()
+		if next == "" {
+			return nil
+		}
+		opts.Continue = next
+	}
+}
@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// pagedResource serves its items in pages honoring the Limit and Continue list options
+type pagedResource struct {
+	dynamic.ResourceInterface
+	items []unstructured.Unstructured
+	calls []metav1.ListOptions
+	err   error
+}
+
+func (r *pagedResource) List(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.calls = append(r.calls, opts)
+	if r.err != nil {
+		return nil, r.err
+	}
+	start := 0
+	if opts.Continue != "" {
+		start, _ = strconv.Atoi(opts.Continue)
+	}
+	end := len(r.items)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+	list := &unstructured.UnstructuredList{Items: r.items[start:end]}
+	if end < len(r.items) {
+		list.SetContinue(strconv.Itoa(end))
+	}
+	return list, nil
+}
+
+func newPagedResource(n int) *pagedResource {
+	items := make([]unstructured.Unstructured, n)
+	for i := range items {
+		items[i].SetName("pod-" + strconv.Itoa(i))
+	}
+	return &pagedResource{items: items}
+}
+
+func TestListPages(t *testing.T) {
+	tests := map[string]struct {
+		items    int
+		pageSize int64
+		pages    int
+	}{
+		"single page":       {items: 3, pageSize: 5, pages: 1},
+		"multiple pages":    {items: 5, pageSize: 2, pages: 3},
+		"exact pages":       {items: 4, pageSize: 2, pages: 2},
+		"chunking disabled": {items: 5, pageSize: 0, pages: 1},
+		"no items":          {items: 0, pageSize: 2, pages: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newPagedResource(test.items)
+			names := make([]string, 0)
+
+			err := ListPages(client, metav1.ListOptions{LabelSelector: "app=foo"}, test.pageSize, func(items []unstructured.Unstructured) error {
+				assert.True(t, test.pageSize == 0 || int64(len(items)) <= test.pageSize)
+				for _, i := range items {
+					names = append(names, i.GetName())
+				}
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Len(t, client.calls, test.pages)
+			assert.Len(t, names, test.items)
+			for _, c := range client.calls {
+				assert.Equal(t, test.pageSize, c.Limit)
+				assert.Equal(t, "app=foo", c.LabelSelector)
+			}
+		})
+	}
+}
+
+func TestListPages_listError(t *testing.T) {
+	client := &pagedResource{err: errors.New("forbidden")}
+
+	err := ListPages(client, metav1.ListOptions{}, 10, func(items []unstructured.Unstructured) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "forbidden")
+}
+
+func TestListPages_callbackError(t *testing.T) {
+	client := newPagedResource(5)
+
+	err := ListPages(client, metav1.ListOptions{}, 2, func(items []unstructured.Unstructured) error {
+		return errors.New("stop")
+	})
+
+	assert.EqualError(t, err, "stop")
+	assert.Len(t, client.calls, 1)
+}
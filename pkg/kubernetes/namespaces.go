@@ -1,33 +1,217 @@
 package kubernetes
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var replacer = strings.NewReplacer(
-	"*", "\\\\*",
+// Syntaxes of namespace patterns
+const (
+	// ExactPattern matches the namespace with the given name
+	ExactPattern string = "exact"
+	// GlobPattern matches the namespaces whose names match the glob, * matches any sequence of characters and ? a single character
+	GlobPattern string = "glob"
+	// RegexPattern matches the namespaces whose names match the whole regular expression
+	RegexPattern string = "regex"
 )
 
-// Namespaces gets the list of blacklisted namespaces
-func (k *API) Namespaces(blacklistedNamespaces []string) (map[string]interface{}, error) {
-	namespaces, err := k.Client.CoreV1().Namespaces().List(metav1.ListOptions{})
+// NamespacePattern matches namespaces by name
+type NamespacePattern struct {
+	Syntax     string
+	Expression string
+	re         *regexp.Regexp
+}
+
+// ParseNamespacePattern parses a pattern in the format [exact:|glob:|regex:]expression.
+// Patterns without syntax are globs if they contain * or ?, otherwise they are exact names.
+func ParseNamespacePattern(s string) (NamespacePattern, error) {
+	s = strings.TrimSpace(s)
+	p := NamespacePattern{Expression: s}
+	if parts := strings.SplitN(s, ":", 2); len(parts) == 2 {
+		p.Syntax, p.Expression = parts[0], parts[1]
+	} else if strings.ContainsAny(s, "*?") {
+		p.Syntax = GlobPattern
+	} else {
+		p.Syntax = ExactPattern
+	}
+	if p.Expression == "" {
+		return NamespacePattern{}, fmt.Errorf("invalid namespace pattern %q: empty expression", s)
+	}
+	var expr string
+	switch p.Syntax {
+	case ExactPattern:
+		expr = regexp.QuoteMeta(p.Expression)
+	case GlobPattern:
+		expr = globReplacer.Replace(regexp.QuoteMeta(p.Expression))
+	case RegexPattern:
+		expr = p.Expression
+	default:
+		return NamespacePattern{}, fmt.Errorf("invalid namespace pattern %q: unsupported syntax %s (%s, %s, %s)", s, p.Syntax, ExactPattern, GlobPattern, RegexPattern)
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return NamespacePattern{}, fmt.Errorf("invalid namespace pattern %q: %s", s, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// globReplacer turns the quoted wildcards of a glob into their regular expression counterparts
+var globReplacer = strings.NewReplacer(
+	`\*`, ".*",
+	`\?`, ".",
+)
+
+// ParseNamespacePatterns parses a list of patterns. Every item may contain multiple comma separated patterns.
+// Commas inside brackets are part of the pattern, so regular expressions like ^team-[a-z]{2,4}$ are not split.
+// Since list flags are split on every comma before, the items are joined again before they are split.
+// Empty patterns are ignored.
+func ParseNamespacePatterns(entries []string) ([]NamespacePattern, error) {
+	items, err := splitPatterns(strings.Join(entries, ","))
 	if err != nil {
 		return nil, err
 	}
+	patterns := make([]NamespacePattern, 0)
+	for _, s := range items {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		p, err := ParseNamespacePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
 
-	blacklist := make(map[string]interface{}, 0)
-	for _, m := range blacklistedNamespaces {
-		for _, n := range namespaces.Items {
-			t := replacer.Replace(strings.Trim(m, ","))
-			re := regexp.MustCompile(t)
-			if re.MatchString(n.Name) {
-				blacklist[n.Name] = struct{}{}
+// splitPatterns splits s at the commas outside of (), [] and {}. Escaped characters are skipped.
+func splitPatterns(s string) ([]string, error) {
+	items := make([]string, 0)
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("invalid namespace patterns %q: unbalanced %q", s, s[i])
+			}
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
 			}
 		}
 	}
+	if depth != 0 {
+		return nil, fmt.Errorf("invalid namespace patterns %q: unbalanced brackets", s)
+	}
+	return append(items, s[start:]), nil
+}
+
+// Matches returns true if the pattern matches the namespace name
+func (p NamespacePattern) Matches(name string) bool {
+	return p.re.MatchString(name)
+}
+
+func (p NamespacePattern) String() string {
+	return p.Syntax + ":" + p.Expression
+}
+
+// NamespaceFilter excludes namespaces by name and labels
+type NamespaceFilter struct {
+	// Blacklist excludes the matching namespaces
+	Blacklist []NamespacePattern
+	// Whitelist excludes the namespaces not matching any of its patterns. An empty whitelist keeps all namespaces.
+	Whitelist []NamespacePattern
+	Labels    LabelFilter
+}
 
-	return blacklist, nil
+// Excludes returns the rule excluding the namespace and true if the namespace is excluded
+func (f NamespaceFilter) Excludes(namespace corev1.Namespace) (string, bool) {
+	for _, p := range f.Blacklist {
+		if p.Matches(namespace.Name) {
+			return "blacklist pattern " + p.String(), true
+		}
+	}
+	if len(f.Whitelist) > 0 {
+		whitelisted := false
+		for _, p := range f.Whitelist {
+			if p.Matches(namespace.Name) {
+				whitelisted = true
+				break
+			}
+		}
+		if !whitelisted {
+			return "whitelist", true
+		}
+	}
+	if rule, ok := f.Labels.Excludes(namespace.Labels); ok {
+		return rule, true
+	}
+	return "", false
+}
+
+// FilterNamespaces gets the sorted names of the namespaces included by the filter and the namespaces
+// excluded by the filter together with the rule excluding them
+func (k *API) FilterNamespaces(filter NamespaceFilter) ([]string, map[string]string, error) {
+	namespaces, err := k.Client.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	included := make([]string, 0)
+	excluded := make(map[string]string, 0)
+	for _, n := range namespaces.Items {
+		if rule, ok := filter.Excludes(n); ok {
+			excluded[n.Name] = rule
+			continue
+		}
+		included = append(included, n.Name)
+	}
+	sort.Strings(included)
+	return included, excluded, nil
+}
+
+// NamespaceScope restricts the namespaces listed by the API server, so objects in excluded namespaces are not transferred
+type NamespaceScope struct {
+	// PerNamespace lists namespaced resources in each of the included namespaces instead of cluster-wide
+	PerNamespace bool
+	// Namespaces are the included namespaces listed if PerNamespace is set
+	Namespaces []string
+	// FieldSelector excludes the excluded namespaces from cluster-wide lists
+	FieldSelector string
+}
+
+// NewNamespaceScope chooses the cheaper way to exclude namespaces on the API server. Resources are listed per
+// namespace if fewer namespaces are included than excluded, otherwise cluster-wide with a field selector
+// excluding the excluded namespaces.
+func NewNamespaceScope(included []string, excluded map[string]string) NamespaceScope {
+	if len(excluded) == 0 {
+		return NamespaceScope{}
+	}
+	if len(included) < len(excluded) {
+		return NamespaceScope{
+			PerNamespace: true,
+			Namespaces:   included,
+		}
+	}
+	names := make([]string, 0, len(excluded))
+	for name := range excluded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return NamespaceScope{
+		FieldSelector: BlacklistFieldSelector(names),
+	}
 }
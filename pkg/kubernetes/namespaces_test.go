@@ -0,0 +1,172 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespacePatternMatches(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		"exact": {
+			pattern: "kube",
+			matches: []string{"kube"},
+			misses:  []string{"my-kube-app", "kube-system"},
+		},
+		"explicit exact": {
+			pattern: "exact:kube-*",
+			matches: []string{"kube-*"},
+			misses:  []string{"kube-system"},
+		},
+		"glob prefix": {
+			pattern: "kube-*",
+			matches: []string{"kube-system", "kube-"},
+			misses:  []string{"my-kube-system"},
+		},
+		"glob suffix": {
+			pattern: "*-system",
+			matches: []string{"kube-system", "cert-manager-system"},
+			misses:  []string{"kube-system-2"},
+		},
+		"glob single character": {
+			pattern: "glob:team-?",
+			matches: []string{"team-a"},
+			misses:  []string{"team-ab"},
+		},
+		"glob escapes regular expression characters": {
+			pattern: "glob:app.*",
+			matches: []string{"app.v1"},
+			misses:  []string{"app-v1"},
+		},
+		"anchored regex": {
+			pattern: "regex:kube|istio-.+",
+			matches: []string{"kube", "istio-system"},
+			misses:  []string{"my-kube-app", "istio-"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := ParseNamespacePattern(tt.pattern)
+
+			assert.NoError(t, err)
+			for _, n := range tt.matches {
+				assert.True(t, p.Matches(n), n)
+			}
+			for _, n := range tt.misses {
+				assert.False(t, p.Matches(n), n)
+			}
+		})
+	}
+}
+
+func TestParseNamespacePattern_invalid(t *testing.T) {
+	for _, input := range []string{"regex:(kube", "prefix:kube", "glob:"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseNamespacePattern(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseNamespacePatterns(t *testing.T) {
+	patterns, err := ParseNamespacePatterns([]string{"", "kube-system, default", " *-system"})
+
+	assert.NoError(t, err)
+	assert.Len(t, patterns, 3)
+	assert.Equal(t, "exact:kube-system", patterns[0].String())
+	assert.Equal(t, "exact:default", patterns[1].String())
+	assert.Equal(t, "glob:*-system", patterns[2].String())
+}
+
+func TestParseNamespacePatterns_commaInBrackets(t *testing.T) {
+	// list flags split the regular expression at the comma
+	patterns, err := ParseNamespacePatterns([]string{"kube-system", "regex:^team-[a-z]{2", "4}$", "regex:(a|b)"})
+
+	assert.NoError(t, err)
+	assert.Len(t, patterns, 3)
+	assert.Equal(t, "regex:^team-[a-z]{2,4}$", patterns[1].String())
+	assert.True(t, patterns[1].Matches("team-abc"))
+	assert.Equal(t, "regex:(a|b)", patterns[2].String())
+}
+
+func TestParseNamespacePatterns_unbalanced(t *testing.T) {
+	_, err := ParseNamespacePatterns([]string{"regex:^team-[a-z]{2"})
+	assert.Error(t, err)
+
+	_, err = ParseNamespacePatterns([]string{"regex:team)"})
+	assert.Error(t, err)
+}
+
+func TestFilterNamespaces(t *testing.T) {
+	k := API{
+		Client: fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop-sandbox", Labels: map[string]string{"team": "a", "leanix.net/ignore": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop-legacy", Labels: map[string]string{"team": "b"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "blog", Labels: map[string]string{"team": "a"}}},
+		),
+	}
+	blacklist, err := ParseNamespacePatterns([]string{"kube-*"})
+	assert.NoError(t, err)
+	whitelist, err := ParseNamespacePatterns([]string{"kube-system", "shop*"})
+	assert.NoError(t, err)
+	labels, err := NewLabelFilter("team=a", DefaultExcludeSelector)
+	assert.NoError(t, err)
+
+	included, excluded, err := k.FilterNamespaces(NamespaceFilter{
+		Blacklist: blacklist,
+		Whitelist: whitelist,
+		Labels:    labels,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, included)
+	assert.Equal(t, map[string]string{
+		"kube-system":  "blacklist pattern glob:kube-*",
+		"shop-sandbox": "exclude label selector leanix.net/ignore=true",
+		"shop-legacy":  "label selector team=a",
+		"blog":         "whitelist",
+	}, excluded)
+}
+
+func TestNewNamespaceScope(t *testing.T) {
+	tests := map[string]struct {
+		included []string
+		excluded map[string]string
+		expected NamespaceScope
+	}{
+		"nothing excluded": {
+			included: []string{"default", "shop"},
+			excluded: map[string]string{},
+			expected: NamespaceScope{},
+		},
+		"fewer excluded than included": {
+			included: []string{"default", "shop"},
+			excluded: map[string]string{"kube-system": "blacklist pattern exact:kube-system"},
+			expected: NamespaceScope{FieldSelector: "metadata.namespace!=kube-system"},
+		},
+		"fewer included than excluded": {
+			included: []string{"shop"},
+			excluded: map[string]string{"kube-system": "whitelist", "default": "whitelist"},
+			expected: NamespaceScope{PerNamespace: true, Namespaces: []string{"shop"}},
+		},
+		"field selector is sorted": {
+			included: []string{"shop", "blog"},
+			excluded: map[string]string{"kube-system": "whitelist", "default": "whitelist"},
+			expected: NamespaceScope{FieldSelector: "metadata.namespace!=default,metadata.namespace!=kube-system"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewNamespaceScope(tt.included, tt.excluded))
+		})
+	}
+}
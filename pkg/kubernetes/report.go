@@ -0,0 +1,136 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// FailFast aborts the run on the first resource that fails to be discovered or listed. Resources not
+	// listed yet at that point are not listed anymore.
+	FailFast string = "fail-fast"
+	// FailOnThreshold aborts the run if more resources fail than the configured threshold
+	FailOnThreshold string = "fail-on-threshold"
+	// BestEffort continues the run with the remaining resources regardless of failures
+	BestEffort string = "best-effort"
+)
+
+const (
+	// DiscoveryStage marks failures while discovering the resources of an API group version
+	DiscoveryStage string = "discovery"
+	// ListStage marks failures while listing the instances of a resource
+	ListStage string = "list"
+)
+
+// ResourceFailure describes a resource that could not be discovered or listed
+type ResourceFailure struct {
+	GroupVersion string `json:"groupVersion"`
+	Resource     string `json:"resource,omitempty"`
+	Stage        string `json:"stage"`
+	Error        string `json:"error"`
+}
+
+// RunReport collects the outcome of scanning the resources of a cluster. The failures are sorted by resource
+// and stage, so the report does not depend on the order the resources were discovered or listed in.
+type RunReport struct {
+	ScannedResources int               `json:"scannedResources"`
+	Failures         []ResourceFailure `json:"failures"`
+}
+
+// NewRunReport creates an empty RunReport
+func NewRunReport() *RunReport {
+	return &RunReport{
+		Failures: make([]ResourceFailure, 0),
+	}
+}
+
+// AddDiscoveryFailure records an API group version whose resources could not be discovered
+func (r *RunReport) AddDiscoveryFailure(gv schema.GroupVersion, err error) {
+	r.Failures = append(r.Failures, ResourceFailure{
+		GroupVersion: gv.String(),
+		Stage:        DiscoveryStage,
+		Error:        err.Error(),
+	})
+	r.sortFailures()
+}
+
+// AddResult records the outcome of listing a resource
+func (r *RunReport) AddResult(result CollectResult) {
+	r.ScannedResources++
+	if result.Err == nil {
+		return
+	}
+	r.Failures = append(r.Failures, ResourceFailure{
+		GroupVersion: result.GVR.GroupVersion().String(),
+		Resource:     result.GVR.Resource,
+		Stage:        ListStage,
+		Error:        result.Err.Error(),
+	})
+	r.sortFailures()
+}
+
+func (r *RunReport) sortFailures() {
+	sort.SliceStable(r.Failures, func(i, j int) bool {
+		a, b := r.Failures[i], r.Failures[j]
+		if resourceName(a) != resourceName(b) {
+			return resourceName(a) < resourceName(b)
+		}
+		return a.Stage < b.Stage
+	})
+}
+
+// ErrorPolicy decides whether a run is aborted because of failed resources
+type ErrorPolicy struct {
+	Mode string
+	// Threshold is the number of failed resources tolerated in FailOnThreshold mode
+	Threshold int
+}
+
+// NewErrorPolicy validates the given mode and threshold and creates an ErrorPolicy
+func NewErrorPolicy(mode string, threshold int) (ErrorPolicy, error) {
+	switch mode {
+	case FailFast, BestEffort:
+	case FailOnThreshold:
+		if threshold < 0 {
+			return ErrorPolicy{}, fmt.Errorf("error threshold must not be negative")
+		}
+	default:
+		return ErrorPolicy{}, fmt.Errorf("unsupported error policy %s (%s, %s, %s)", mode, FailFast, FailOnThreshold, BestEffort)
+	}
+	return ErrorPolicy{
+		Mode:      mode,
+		Threshold: threshold,
+	}, nil
+}
+
+// StopOnError returns true if listing resources is stopped on the first failure
+func (p ErrorPolicy) StopOnError() bool {
+	return p.Mode == FailFast
+}
+
+// Check returns an error if the failures in the report violate the policy.
+// In FailFast mode the error describes the first of the sorted failures.
+func (p ErrorPolicy) Check(r *RunReport) error {
+	failed := len(r.Failures)
+	switch p.Mode {
+	case FailFast:
+		if failed > 0 {
+			f := r.Failures[0]
+			return fmt.Errorf("%s of %s failed: %s", f.Stage, resourceName(f), f.Error)
+		}
+	case FailOnThreshold:
+		if failed > p.Threshold {
+			return fmt.Errorf("%d resources failed, exceeding the threshold of %d", failed, p.Threshold)
+		}
+	}
+	return nil
+}
+
+func resourceName(f ResourceFailure) string {
+	if f.Resource == "" {
+		return f.GroupVersion
+	}
+	return f.GroupVersion + "/" + f.Resource
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRunReport(t *testing.T) {
+	r := NewRunReport()
+
+	r.AddDiscoveryFailure(schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}, errors.New("service unavailable"))
+	r.AddResult(CollectResult{GVR: podsResource})
+	r.AddResult(CollectResult{GVR: deploymentsResource, Err: errors.New("forbidden")})
+
+	assert.Equal(t, 2, r.ScannedResources)
+	assert.Equal(t, []ResourceFailure{
+		{GroupVersion: "apps/v1", Resource: "deployments", Stage: ListStage, Error: "forbidden"},
+		{GroupVersion: "metrics.k8s.io/v1beta1", Stage: DiscoveryStage, Error: "service unavailable"},
+	}, r.Failures)
+}
+
+func TestNewErrorPolicy_invalid(t *testing.T) {
+	_, err := NewErrorPolicy("ignore", 0)
+	assert.Error(t, err)
+
+	_, err = NewErrorPolicy(FailOnThreshold, -1)
+	assert.Error(t, err)
+}
+
+func TestErrorPolicyCheck_failFastReportsFirstSortedFailure(t *testing.T) {
+	policy, err := NewErrorPolicy(FailFast, 0)
+	assert.NoError(t, err)
+	r := NewRunReport()
+	r.AddDiscoveryFailure(schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}, errors.New("service unavailable"))
+	r.AddDiscoveryFailure(schema.GroupVersion{Group: "custom.metrics.k8s.io", Version: "v1beta1"}, errors.New("service unavailable"))
+	r.AddResult(CollectResult{GVR: secretsResource, Err: errors.New("forbidden")})
+
+	err = policy.Check(r)
+
+	assert.EqualError(t, err, "discovery of custom.metrics.k8s.io/v1beta1 failed: service unavailable")
+	assert.True(t, policy.StopOnError())
+}
+
+func TestErrorPolicyCheck(t *testing.T) {
+	failed := NewRunReport()
+	failed.AddResult(CollectResult{GVR: podsResource, Err: errors.New("forbidden")})
+	failed.AddResult(CollectResult{GVR: secretsResource, Err: errors.New("forbidden")})
+
+	tests := map[string]struct {
+		mode      string
+		threshold int
+		report    *RunReport
+		fails     bool
+	}{
+		"fail-fast without failures":           {mode: FailFast, report: NewRunReport(), fails: false},
+		"fail-fast with failures":              {mode: FailFast, report: failed, fails: true},
+		"fail-on-threshold below threshold":    {mode: FailOnThreshold, threshold: 2, report: failed, fails: false},
+		"fail-on-threshold exceeded threshold": {mode: FailOnThreshold, threshold: 1, report: failed, fails: true},
+		"best-effort with failures":            {mode: BestEffort, report: failed, fails: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy, err := NewErrorPolicy(test.mode, test.threshold)
+			assert.NoError(t, err)
+
+			err = policy.Check(test.report)
+
+			if test.fails {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
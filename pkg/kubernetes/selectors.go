@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// DefaultExcludeSelector excludes the namespaces and objects that are labeled to be ignored by the connector
+const DefaultExcludeSelector string = "leanix.net/ignore=true"
+
+// LabelFilter selects namespaces or objects by their labels. The zero value matches all labels.
+type LabelFilter struct {
+	// Include selects the labels that are kept. Everything is kept if the selector is empty.
+	Include labels.Selector
+	// Exclude selects the labels that are dropped even if they are included. Nothing is dropped if the selector is empty.
+	Exclude labels.Selector
+}
+
+// NewLabelFilter parses the include and exclude label selectors, e.g. "team in (a,b)" or "leanix.net/ignore=true"
+func NewLabelFilter(include string, exclude string) (LabelFilter, error) {
+	includeSelector, err := labels.Parse(include)
+	if err != nil {
+		return LabelFilter{}, fmt.Errorf("invalid include selector %q: %s", include, err)
+	}
+	excludeSelector := labels.Nothing()
+	if exclude != "" {
+		excludeSelector, err = labels.Parse(exclude)
+		if err != nil {
+			return LabelFilter{}, fmt.Errorf("invalid exclude selector %q: %s", exclude, err)
+		}
+	}
+	return LabelFilter{
+		Include: includeSelector,
+		Exclude: excludeSelector,
+	}, nil
+}
+
+// Matches returns true if the labels are included and not excluded
+func (f LabelFilter) Matches(l map[string]string) bool {
+	_, excluded := f.Excludes(l)
+	return !excluded
+}
+
+// ListSelector returns the label selector passed to list calls, so the API server only returns the matching labels.
+// An exclude selector with a single requirement is negated and added to the include selector. Other exclude
+// selectors cannot be negated into a single selector and are only applied by Matches.
+func (f LabelFilter) ListSelector() string {
+	include := f.Include
+	if include == nil {
+		include = labels.NewSelector()
+	}
+	if f.Exclude == nil {
+		return include.String()
+	}
+	requirements, selectable := f.Exclude.Requirements()
+	if !selectable || len(requirements) != 1 {
+		return include.String()
+	}
+	negated, ok := negate(requirements[0])
+	if !ok {
+		return include.String()
+	}
+	return include.Add(*negated).String()
+}
+
+// negate returns the requirement matching exactly the labels not matched by r
+func negate(r labels.Requirement) (*labels.Requirement, bool) {
+	var op selection.Operator
+	switch r.Operator() {
+	case selection.Equals, selection.DoubleEquals:
+		op = selection.NotEquals
+	case selection.NotEquals:
+		op = selection.Equals
+	case selection.In:
+		op = selection.NotIn
+	case selection.NotIn:
+		op = selection.In
+	case selection.Exists:
+		op = selection.DoesNotExist
+	case selection.DoesNotExist:
+		op = selection.Exists
+	default:
+		return nil, false
+	}
+	negated, err := labels.NewRequirement(r.Key(), op, r.Values().List())
+	if err != nil {
+		return nil, false
+	}
+	return negated, true
+}
+
+// Excludes returns the selector excluding the labels and true if the labels are not included or excluded
+func (f LabelFilter) Excludes(l map[string]string) (string, bool) {
+	if f.Include != nil && !f.Include.Matches(labels.Set(l)) {
+		return "label selector " + f.Include.String(), true
+	}
+	if f.Exclude != nil && f.Exclude.Matches(labels.Set(l)) {
+		return "exclude label selector " + f.Exclude.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,92 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelFilterMatches(t *testing.T) {
+	tests := map[string]struct {
+		include string
+		exclude string
+		labels  map[string]string
+		matches bool
+	}{
+		"empty selectors": {
+			labels:  map[string]string{"team": "a"},
+			matches: true,
+		},
+		"included": {
+			include: "team in (a,b)",
+			labels:  map[string]string{"team": "a"},
+			matches: true,
+		},
+		"not included": {
+			include: "team in (a,b)",
+			labels:  map[string]string{"team": "c"},
+			matches: false,
+		},
+		"excluded": {
+			exclude: DefaultExcludeSelector,
+			labels:  map[string]string{"team": "a", "leanix.net/ignore": "true"},
+			matches: false,
+		},
+		"not excluded": {
+			exclude: DefaultExcludeSelector,
+			labels:  map[string]string{"team": "a", "leanix.net/ignore": "false"},
+			matches: true,
+		},
+		"included and excluded": {
+			include: "team=a",
+			exclude: DefaultExcludeSelector,
+			labels:  map[string]string{"team": "a", "leanix.net/ignore": "true"},
+			matches: false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewLabelFilter(tt.include, tt.exclude)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matches, f.Matches(tt.labels))
+		})
+	}
+}
+
+func TestLabelFilterListSelector(t *testing.T) {
+	tests := map[string]struct {
+		include  string
+		exclude  string
+		expected string
+	}{
+		"empty selectors":           {expected: ""},
+		"include only":              {include: "team=a", expected: "team=a"},
+		"default exclude selector":  {exclude: DefaultExcludeSelector, expected: "leanix.net/ignore!=true"},
+		"include and exclude":       {include: "team=a", exclude: DefaultExcludeSelector, expected: "leanix.net/ignore!=true,team=a"},
+		"set based exclude":         {exclude: "tier in (cache,db)", expected: "tier notin (cache,db)"},
+		"existence exclude":         {exclude: "sandbox", expected: "!sandbox"},
+		"multiple requirements":     {include: "team=a", exclude: "sandbox,tier=db", expected: "team=a"},
+		"not negatable requirement": {exclude: "priority>5", expected: ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewLabelFilter(tt.include, tt.exclude)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, f.ListSelector())
+		})
+	}
+}
+
+func TestLabelFilterMatches_zeroValue(t *testing.T) {
+	assert.True(t, LabelFilter{}.Matches(map[string]string{"leanix.net/ignore": "true"}))
+}
+
+func TestNewLabelFilter_invalid(t *testing.T) {
+	_, err := NewLabelFilter("team in (a", "")
+	assert.Error(t, err)
+
+	_, err = NewLabelFilter("", "==")
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ResourceWatcher keeps the instances of multiple resources in an in-memory cache using dynamic shared informers
+type ResourceWatcher struct {
+	factory   dynamicinformer.DynamicSharedInformerFactory
+	gvrs      []schema.GroupVersionResource
+	informers []cache.SharedIndexInformer
+	changes   chan struct{}
+	// Filter is called for every cached instance. Instances are dropped if it returns false.
+	Filter func(i *unstructured.Unstructured) bool
+}
+
+// NewResourceWatcher creates a ResourceWatcher for the given resources. The caches are resynced every resync period.
+// Only instances matching the label selector are cached, an empty selector caches all instances.
+func NewResourceWatcher(client dynamic.Interface, gvrs []schema.GroupVersionResource, resync time.Duration, labelSelector string) *ResourceWatcher {
+	tweakListOptions := func(opts *metav1.ListOptions) {
+		opts.LabelSelector = labelSelector
+	}
+	w := &ResourceWatcher{
+		factory:   dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, resync, metav1.NamespaceAll, tweakListOptions),
+		gvrs:      gvrs,
+		informers: make([]cache.SharedIndexInformer, len(gvrs)),
+		changes:   make(chan struct{}, 1),
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { w.notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) { w.notify() },
+		DeleteFunc: func(obj interface{}) { w.notify() },
+	}
+	for i, gvr := range gvrs {
+		informer := w.factory.ForResource(gvr).Informer()
+		informer.AddEventHandler(handler)
+		w.informers[i] = informer
+	}
+	return w
+}
+
+// Start starts the informers and waits at most timeout for their caches to be filled.
+// Caches that are not filled in time continue to sync in the background.
+func (w *ResourceWatcher) Start(stopCh <-chan struct{}, timeout time.Duration) {
+	w.factory.Start(stopCh)
+	syncStopCh := make(chan struct{})
+	timer := time.AfterFunc(timeout, func() { close(syncStopCh) })
+	defer timer.Stop()
+	for _, informer := range w.informers {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+		cache.WaitForCacheSync(syncStopCh, informer.HasSynced)
+	}
+}
+
+// Changes returns a channel that receives a value after instances were added, updated or deleted.
+// Multiple changes are coalesced until the value is received.
+func (w *ResourceWatcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Collect hands deep copies of the cached instances to handle in the order of the watched resources.
+// Instances are sorted by namespace and name and handed over resource by resource.
+// Resources whose cache is not filled yet are returned with an error.
+func (w *ResourceWatcher) Collect(handle PageHandler) []CollectResult {
+	results := make([]CollectResult, len(w.gvrs))
+	for i, gvr := range w.gvrs {
+		start := time.Now()
+		results[i] = CollectResult{
+			GVR: gvr,
+		}
+		if !w.informers[i].HasSynced() {
+			results[i].Err = fmt.Errorf("cache of %s not synced", gvr.String())
+			continue
+		}
+		items := make([]unstructured.Unstructured, 0)
+		for _, obj := range w.informers[i].GetStore().List() {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if w.Filter != nil && !w.Filter(u) {
+				continue
+			}
+			items = append(items, *u.DeepCopy())
+		}
+		sortByNamespaceAndName(items)
+		results[i].Count = len(items)
+		if len(items) > 0 {
+			handle(gvr, items)
+		}
+		results[i].Duration = time.Since(start)
+	}
+	return results
+}
+
+func (w *ResourceWatcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+func sortByNamespaceAndName(items []unstructured.Unstructured) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
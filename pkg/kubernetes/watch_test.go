@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestResourceWatcher(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		newUnstructured("v1", "Pod", "default", "nginx"),
+		newUnstructured("v1", "Pod", "kube-system", "kube-proxy"),
+		newUnstructured("apps/v1", "Deployment", "default", "nginx"),
+	)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	watcher := NewResourceWatcher(client, []schema.GroupVersionResource{podsResource, deploymentsResource}, 0, "")
+	watcher.Filter = func(i *unstructured.Unstructured) bool {
+		return i.GetNamespace() != "kube-system"
+	}
+
+	watcher.Start(stopCh, 10*time.Second)
+	items := handledItems{}
+	results := watcher.Collect(items.handle)
+
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Count)
+	assert.Len(t, items[podsResource], 1)
+	assert.Len(t, items[deploymentsResource], 1)
+	assert.Equal(t, "Deployment", items[deploymentsResource][0].GetKind())
+
+	// drain the notifications of the initial list
+	select {
+	case <-watcher.Changes():
+	default:
+	}
+	_, err := client.Resource(podsResource).Namespace("default").Create(newUnstructured("v1", "Pod", "default", "apache"), metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	select {
+	case <-watcher.Changes():
+	case <-time.After(10 * time.Second):
+		t.Fatal("no change notification received")
+	}
+	items = handledItems{}
+	watcher.Collect(items.handle)
+	assert.Len(t, items[podsResource], 2)
+	assert.Equal(t, "apache", items[podsResource][0].GetName())
+	assert.Equal(t, "nginx", items[podsResource][1].GetName())
+}
+
+func TestResourceWatcher_notSynced(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	watcher := NewResourceWatcher(client, []schema.GroupVersionResource{podsResource}, 0, "")
+
+	results := watcher.Collect(handledItems{}.handle)
+
+	assert.Error(t, results[0].Err)
+}
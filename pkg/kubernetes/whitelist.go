@@ -0,0 +1,175 @@
+package kubernetes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultResourceWhitelist is the list of resources scanned when no whitelist is configured
+var DefaultResourceWhitelist = []string{
+	"serviceaccounts",
+	"services",
+	"nodes",
+	"pods",
+	"namespaces",
+	"configmaps",
+	"persistentvolumes",
+	"persistentvolumeclaims",
+	"replicationcontrollers",
+	"resourcequotas",
+	"apps/deployments",
+	"apps/statefulsets",
+	"apps/daemonsets",
+	"apps/replicasets",
+	"apiextensions.k8s.io/customresourcedefinitions",
+	"rbac.authorization.k8s.io/clusterrolebindings",
+	"rbac.authorization.k8s.io/rolebindings",
+	"rbac.authorization.k8s.io/clusterroles",
+	"rbac.authorization.k8s.io/roles",
+	"networking.k8s.io/ingresses",
+	"networking.k8s.io/networkpolicies",
+	"autoscaling/horizontalpodautoscalers",
+	"policy/podsecuritypolicies",
+	"storage.k8s.io/storageclasses",
+	"batch/cronjobs",
+	"batch/jobs",
+}
+
+// ResourceWhitelistEntry selects resources by API group, version and resource name.
+// All fields support shell-style wildcards. An empty version matches every version.
+type ResourceWhitelistEntry struct {
+	Group    string
+	Version  string
+	Resource string
+}
+
+// ResourceWhitelist is a list of entries that select the resources the connector scans
+type ResourceWhitelist []ResourceWhitelistEntry
+
+// ParseResourceWhitelistEntry parses an entry in the format resource, group/resource or group/version/resource.
+// The core API group is selected with an empty group e.g. /v1/pods.
+func ParseResourceWhitelistEntry(s string) (ResourceWhitelistEntry, error) {
+	var e ResourceWhitelistEntry
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	switch len(parts) {
+	case 1:
+		e.Resource = parts[0]
+	case 2:
+		e.Group, e.Resource = parts[0], parts[1]
+	case 3:
+		e.Group, e.Version, e.Resource = parts[0], parts[1], parts[2]
+	default:
+		return e, fmt.Errorf("invalid resource whitelist entry %q: expected [group/[version/]]resource", s)
+	}
+	if e.Resource == "" {
+		return e, fmt.Errorf("invalid resource whitelist entry %q: resource must not be empty", s)
+	}
+	for _, p := range parts {
+		if _, err := path.Match(p, ""); err != nil {
+			return e, fmt.Errorf("invalid resource whitelist entry %q: %s", s, err)
+		}
+	}
+	return e, nil
+}
+
+// ParseResourceWhitelist parses a list of whitelist entries. Every item may contain multiple comma separated entries.
+func ParseResourceWhitelist(entries []string) (ResourceWhitelist, error) {
+	whitelist := make(ResourceWhitelist, 0)
+	for _, item := range entries {
+		for _, s := range strings.Split(item, ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			e, err := ParseResourceWhitelistEntry(s)
+			if err != nil {
+				return nil, err
+			}
+			whitelist = append(whitelist, e)
+		}
+	}
+	return whitelist, nil
+}
+
+// LoadResourceWhitelist reads a whitelist file with one entry per line. Empty lines and lines starting with # are ignored.
+func LoadResourceWhitelist(filename string) (ResourceWhitelist, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ParseResourceWhitelist(entries)
+}
+
+// Matches returns true if the entry selects the given group/version/resource
+func (e ResourceWhitelistEntry) Matches(gvr schema.GroupVersionResource) bool {
+	if !match(e.Group, gvr.Group) || !match(e.Resource, gvr.Resource) {
+		return false
+	}
+	return e.Version == "" || match(e.Version, gvr.Version)
+}
+
+// String returns the entry in the format group/version/resource
+func (e ResourceWhitelistEntry) String() string {
+	return strings.Join([]string{e.Group, e.Version, e.Resource}, "/")
+}
+
+// Contains returns true if any entry of the whitelist selects the given group/version/resource
+func (w ResourceWhitelist) Contains(gvr schema.GroupVersionResource) bool {
+	for _, e := range w {
+		if e.Matches(gvr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsGroupVersion returns true if any entry of the whitelist may select resources of the given group version
+func (w ResourceWhitelist) ContainsGroupVersion(gv schema.GroupVersion) bool {
+	for _, e := range w {
+		if match(e.Group, gv.Group) && (e.Version == "" || match(e.Version, gv.Version)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unmatched returns all entries of the whitelist that do not select any of the resources served by the cluster
+func (w ResourceWhitelist) Unmatched(served map[schema.GroupVersionResource]struct{}) ResourceWhitelist {
+	unmatched := make(ResourceWhitelist, 0)
+	for _, e := range w {
+		found := false
+		for gvr := range served {
+			if e.Matches(gvr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, e)
+		}
+	}
+	return unmatched
+}
+
+func match(pattern string, name string) bool {
+	// the pattern is validated while parsing, so the error can be ignored
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
@@ -0,0 +1,121 @@
+package kubernetes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseResourceWhitelistEntry(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected ResourceWhitelistEntry
+	}{
+		"core resource": {
+			input:    "pods",
+			expected: ResourceWhitelistEntry{Resource: "pods"},
+		},
+		"group and resource": {
+			input:    "apps/deployments",
+			expected: ResourceWhitelistEntry{Group: "apps", Resource: "deployments"},
+		},
+		"group, version and resource": {
+			input:    "networking.istio.io/v1beta1/gateways",
+			expected: ResourceWhitelistEntry{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"},
+		},
+		"core group with version": {
+			input:    "/v1/pods",
+			expected: ResourceWhitelistEntry{Version: "v1", Resource: "pods"},
+		},
+		"wildcard": {
+			input:    " *.cert-manager.io/* ",
+			expected: ResourceWhitelistEntry{Group: "*.cert-manager.io", Resource: "*"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e, err := ParseResourceWhitelistEntry(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, e)
+		})
+	}
+}
+
+func TestParseResourceWhitelistEntry_invalid(t *testing.T) {
+	for _, input := range []string{"", "apps/", "a/b/c/d", "apps/[deployments"} {
+		_, err := ParseResourceWhitelistEntry(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestParseResourceWhitelist(t *testing.T) {
+	whitelist, err := ParseResourceWhitelist([]string{"pods,", "apps/deployments, batch/jobs", ""})
+	assert.NoError(t, err)
+
+	assert.Equal(t, ResourceWhitelist{
+		{Resource: "pods"},
+		{Group: "apps", Resource: "deployments"},
+		{Group: "batch", Resource: "jobs"},
+	}, whitelist)
+}
+
+func TestLoadResourceWhitelist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "whitelist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "whitelist")
+	content := "# core\npods\n\n  apps/deployments\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	whitelist, err := LoadResourceWhitelist(filename)
+	assert.NoError(t, err)
+
+	assert.Equal(t, ResourceWhitelist{
+		{Resource: "pods"},
+		{Group: "apps", Resource: "deployments"},
+	}, whitelist)
+}
+
+func TestDefaultResourceWhitelist(t *testing.T) {
+	whitelist, err := ParseResourceWhitelist(DefaultResourceWhitelist)
+	assert.NoError(t, err)
+
+	assert.True(t, whitelist.Contains(schema.GroupVersionResource{Version: "v1", Resource: "pods"}))
+	assert.True(t, whitelist.Contains(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}))
+	assert.False(t, whitelist.Contains(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "pods"}))
+	assert.False(t, whitelist.Contains(schema.GroupVersionResource{Version: "v1", Resource: "secrets"}))
+}
+
+func TestResourceWhitelistContains(t *testing.T) {
+	whitelist, err := ParseResourceWhitelist([]string{"*.cert-manager.io/*", "networking.istio.io/v1beta1/gateways"})
+	assert.NoError(t, err)
+
+	assert.True(t, whitelist.Contains(schema.GroupVersionResource{Group: "acme.cert-manager.io", Version: "v1", Resource: "orders"}))
+	assert.True(t, whitelist.Contains(schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}))
+	assert.False(t, whitelist.Contains(schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "gateways"}))
+	assert.False(t, whitelist.Contains(schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}))
+}
+
+func TestResourceWhitelistUnmatched(t *testing.T) {
+	whitelist, err := ParseResourceWhitelist([]string{"pods", "policy/podsecuritypolicies", "*.cert-manager.io/*"})
+	assert.NoError(t, err)
+	served := map[schema.GroupVersionResource]struct{}{
+		{Version: "v1", Resource: "pods"}: struct{}{},
+	}
+
+	unmatched := whitelist.Unmatched(served)
+
+	assert.Equal(t, ResourceWhitelist{
+		{Group: "policy", Resource: "podsecuritypolicies"},
+		{Group: "*.cert-manager.io", Resource: "*"},
+	}, unmatched)
+}
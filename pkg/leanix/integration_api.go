@@ -1,9 +1,9 @@
 package leanix
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -52,9 +52,9 @@ func Authenticate(fqdn string, token string) (string, error) {
 	return authResponse.AccessToken, nil
 }
 
-// Upload uploads the generated LDIF to the Integration API and response with id
-func Upload(fqdn string, accessToken string, ldif []byte) (SyncRunResponse, error) {
-	body := bytes.NewReader(ldif)
+// UploadStream uploads the LDIF read from body to the Integration API and response with id.
+// The request body is streamed, so the LDIF does not have to be held in memory.
+func UploadStream(fqdn string, accessToken string, body io.Reader) (SyncRunResponse, error) {
 	req, err := http.NewRequest("POST", "https://"+fqdn+"/services/integration-api/v1/synchronizationRuns", body)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+accessToken)
@@ -0,0 +1,124 @@
+package mapper
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NodeResources are the compute resources of nodes and the resources committed to the pods scheduled on them
+type NodeResources struct {
+	Capacity    Resources `json:"capacity"`
+	Allocatable Resources `json:"allocatable"`
+	// Requests and Limits are the sums of the effective requests and limits of the running pods scheduled on the nodes
+	Requests Resources `json:"requests"`
+	Limits   Resources `json:"limits"`
+	// RequestCommitment and LimitCommitment are the ratios of the requests and limits to the allocatable resources
+	RequestCommitment Commitment `json:"requestCommitment"`
+	LimitCommitment   Commitment `json:"limitCommitment"`
+}
+
+// Commitment is the ratio of committed to allocatable resources, e.g. 1.5 for limits of 150% of the allocatable memory
+type Commitment struct {
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+}
+
+// PodResources sums the requests and limits of the running pods by node
+type PodResources struct {
+	requests map[string]Resources
+	limits   map[string]Resources
+}
+
+// NewPodResources creates an empty PodResources
+func NewPodResources() *PodResources {
+	return &PodResources{
+		requests: make(map[string]Resources),
+		limits:   make(map[string]Resources),
+	}
+}
+
+// Add adds the effective requests and limits of a pod to its node. Pods that are not scheduled or
+// already terminated and all other objects are ignored.
+func (r *PodResources) Add(i *unstructured.Unstructured) error {
+	if i.GetKind() != "Pod" || i.GroupVersionKind().Group != "" {
+		return nil
+	}
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &pod); err != nil {
+		return err
+	}
+	if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil
+	}
+	requests, limits := effectiveResources(pod.Spec)
+	r.requests[pod.Spec.NodeName] = addResources(r.requests[pod.Spec.NodeName], requests)
+	r.limits[pod.Spec.NodeName] = addResources(r.limits[pod.Spec.NodeName], limits)
+	return nil
+}
+
+// Node returns the sums of the requests and limits of the pods scheduled on the node
+func (r *PodResources) Node(name string) (Resources, Resources) {
+	return r.requests[name], r.limits[name]
+}
+
+// aggregrateNodeResources sums the resources of the nodes and of the pods scheduled on them.
+// The pod resources are optional.
+func aggregrateNodeResources(nodes []corev1.Node, pods *PodResources) NodeResources {
+	var resources NodeResources
+	for _, n := range nodes {
+		resources.Capacity = addResources(resources.Capacity, mapResources(n.Status.Capacity))
+		resources.Allocatable = addResources(resources.Allocatable, mapResources(n.Status.Allocatable))
+		if pods != nil {
+			requests, limits := pods.Node(n.Name)
+			resources.Requests = addResources(resources.Requests, requests)
+			resources.Limits = addResources(resources.Limits, limits)
+		}
+	}
+	resources.RequestCommitment = commitment(resources.Requests, resources.Allocatable)
+	resources.LimitCommitment = commitment(resources.Limits, resources.Allocatable)
+	return resources
+}
+
+// effectiveResources returns the requests and limits the scheduler accounts for a pod, which are the sums of the
+// containers or the highest values of the init containers if those are higher
+func effectiveResources(spec corev1.PodSpec) (Resources, Resources) {
+	var requests, limits Resources
+	for _, c := range spec.Containers {
+		requests = addResources(requests, mapResources(c.Resources.Requests))
+		limits = addResources(limits, mapResources(c.Resources.Limits))
+	}
+	for _, c := range spec.InitContainers {
+		requests = maxResources(requests, mapResources(c.Resources.Requests))
+		limits = maxResources(limits, mapResources(c.Resources.Limits))
+	}
+	return requests, limits
+}
+
+func addResources(a Resources, b Resources) Resources {
+	return Resources{
+		CPUMillicores: a.CPUMillicores + b.CPUMillicores,
+		MemoryBytes:   a.MemoryBytes + b.MemoryBytes,
+	}
+}
+
+func maxResources(a Resources, b Resources) Resources {
+	if b.CPUMillicores > a.CPUMillicores {
+		a.CPUMillicores = b.CPUMillicores
+	}
+	if b.MemoryBytes > a.MemoryBytes {
+		a.MemoryBytes = b.MemoryBytes
+	}
+	return a
+}
+
+func commitment(committed Resources, allocatable Resources) Commitment {
+	var c Commitment
+	if allocatable.CPUMillicores > 0 {
+		c.CPU = float64(committed.CPUMillicores) / float64(allocatable.CPUMillicores)
+	}
+	if allocatable.MemoryBytes > 0 {
+		c.Memory = float64(committed.MemoryBytes) / float64(allocatable.MemoryBytes)
+	}
+	return c
+}
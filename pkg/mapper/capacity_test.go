@@ -0,0 +1,90 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPodResources(t *testing.T) {
+	r := NewPodResources()
+	objects := []*unstructured.Unstructured{
+		pod("default", "app", "nginx", inPhase("Running"), withResources("500m", "1Gi"), onNode("node-1")),
+		pod("default", "app", "nginx", inPhase("Pending"), withResources("250m", "512Mi"), onNode("node-1")),
+		pod("default", "app", "nginx", inPhase("Succeeded"), withResources("1", "1Gi"), onNode("node-1")),
+		pod("default", "app", "nginx", inPhase("Running"), withResources("1", "2Gi"), onNode("node-2")),
+		pod("default", "app", "nginx", inPhase("Pending"), withResources("1", "2Gi")),
+		deployment(),
+	}
+	for _, o := range objects {
+		assert.NoError(t, r.Add(o))
+	}
+
+	requests, limits := r.Node("node-1")
+	assert.Equal(t, Resources{CPUMillicores: 750, MemoryBytes: 1536 * 1024 * 1024}, requests)
+	assert.Equal(t, requests, limits)
+	requests, _ = r.Node("node-2")
+	assert.Equal(t, Resources{CPUMillicores: 1000, MemoryBytes: 2 * 1024 * 1024 * 1024}, requests)
+	requests, _ = r.Node("node-3")
+	assert.Equal(t, Resources{}, requests)
+}
+
+func TestEffectiveResources(t *testing.T) {
+	container := func(cpu string, memory string) corev1.Container {
+		return corev1.Container{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	spec := corev1.PodSpec{
+		InitContainers: []corev1.Container{container("2", "128Mi")},
+		Containers:     []corev1.Container{container("500m", "256Mi"), container("500m", "256Mi")},
+	}
+
+	requests, limits := effectiveResources(spec)
+
+	assert.Equal(t, Resources{CPUMillicores: 2000, MemoryBytes: 512 * 1024 * 1024}, requests)
+	assert.Equal(t, Resources{}, limits)
+}
+
+func TestAggregrateNodeResources(t *testing.T) {
+	node := func(name string, capacity string, allocatable string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(capacity),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(allocatable),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+			},
+		}
+	}
+	pods := NewPodResources()
+	assert.NoError(t, pods.Add(pod("default", "app", "nginx", inPhase("Running"), withResources("1", "2Gi"), onNode("node-1"))))
+	assert.NoError(t, pods.Add(pod("default", "app", "nginx", inPhase("Running"), withResources("500m", "4Gi"), onNode("node-2"))))
+	assert.NoError(t, pods.Add(pod("default", "app", "nginx", inPhase("Running"), withResources("8", "8Gi"), onNode("other-cluster-node"))))
+
+	resources := aggregrateNodeResources([]corev1.Node{node("node-1", "2", "1900m"), node("node-2", "2", "1100m")}, pods)
+
+	assert.Equal(t, NodeResources{
+		Capacity:          Resources{CPUMillicores: 4000, MemoryBytes: 16 * 1024 * 1024 * 1024},
+		Allocatable:       Resources{CPUMillicores: 3000, MemoryBytes: 8 * 1024 * 1024 * 1024},
+		Requests:          Resources{CPUMillicores: 1500, MemoryBytes: 6 * 1024 * 1024 * 1024},
+		Limits:            Resources{CPUMillicores: 1500, MemoryBytes: 6 * 1024 * 1024 * 1024},
+		RequestCommitment: Commitment{CPU: 0.5, Memory: 0.75},
+		LimitCommitment:   Commitment{CPU: 0.5, Memory: 0.75},
+	}, resources)
+	assert.Equal(t, Commitment{}, aggregrateNodeResources(nil, nil).RequestCommitment)
+}
@@ -1,20 +1,93 @@
 package mapper
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 )
 
+// Kubernetes distributions detected from the server version, the node labels and the API groups
+const (
+	EKSDistribution       string = "EKS"
+	AKSDistribution       string = "AKS"
+	GKEDistribution       string = "GKE"
+	OpenShiftDistribution string = "OpenShift"
+	K3sDistribution       string = "k3s"
+)
+
+// ClusterInfo is the information about the control plane of a cluster
+type ClusterInfo struct {
+	// ServerVersion is the git version of the API server, e.g. v1.18.14
+	ServerVersion string
+	// APIGroups are the names of the API groups served by the cluster
+	APIGroups []string
+}
+
 // MapNodes maps a list of nodes and a given cluster name into a KubernetesObject.
 // In the process it aggregates the information from muliple nodes into one cluster object.
-func MapNodes(clusterName string, nodes *corev1.NodeList) (*KubernetesObject, error) {
-	nodeAggregate, err := aggregrateNodes(nodes)
-	if err != nil {
-		return nil, err
-	}
+// The topology and instance types of the nodes are read from the given node labels.
+// The requests and limits of the pods scheduled on the nodes are taken from the optional pod resources.
+// The cloud provider and the distribution of the cluster are derived from the nodes and the cluster info.
+func MapNodes(clusterName string, nodes *corev1.NodeList, nodeLabels NodeLabels, pods *PodResources, info ClusterInfo) *KubernetesObject {
+	nodeAggregate := aggregrateNodes(nodes, nodeLabels, pods)
 	nodeAggregate["clusterName"] = clusterName
+	nodeAggregate["serverVersion"] = info.ServerVersion
+	nodeAggregate["cloudProvider"] = cloudProvider(nodes.Items)
+	nodeAggregate["distribution"] = distribution(nodes.Items, info)
 	return &KubernetesObject{
 		ID:   clusterName,
 		Type: "Cluster",
 		Data: nodeAggregate,
-	}, nil
+	}
+}
+
+// cloudProvider returns the provider of the first node by name with a provider id in the format <provider>://<id>,
+// e.g. aws, azure or gce. An empty string is returned if no node has a provider id.
+func cloudProvider(nodes []corev1.Node) string {
+	for _, n := range sortedNodes(nodes) {
+		if parts := strings.SplitN(n.Spec.ProviderID, "://", 2); len(parts) == 2 && parts[0] != "" {
+			return parts[0]
+		}
+	}
+	return ""
+}
+
+// distribution detects the Kubernetes distribution from the well-known API groups, server version suffixes and
+// node labels of the managed offerings. An empty string is returned for unknown distributions.
+func distribution(nodes []corev1.Node, info ClusterInfo) string {
+	for _, g := range info.APIGroups {
+		if strings.HasSuffix(g, ".openshift.io") {
+			return OpenShiftDistribution
+		}
+	}
+	switch {
+	case strings.Contains(info.ServerVersion, "-eks-"):
+		return EKSDistribution
+	case strings.Contains(info.ServerVersion, "-gke."):
+		return GKEDistribution
+	case strings.Contains(info.ServerVersion, "+k3s"):
+		return K3sDistribution
+	}
+	for _, n := range sortedNodes(nodes) {
+		switch {
+		case hasLabel(n, "eks.amazonaws.com/nodegroup", "eks.amazonaws.com/compute-type"):
+			return EKSDistribution
+		case hasLabel(n, "kubernetes.azure.com/cluster", "kubernetes.azure.com/role"):
+			return AKSDistribution
+		case hasLabel(n, "cloud.google.com/gke-nodepool"):
+			return GKEDistribution
+		case n.Labels["node.kubernetes.io/instance-type"] == "k3s" || strings.HasPrefix(n.Spec.ProviderID, "k3s://"):
+			return K3sDistribution
+		}
+	}
+	return ""
+}
+
+func hasLabel(n corev1.Node, keys ...string) bool {
+	for _, k := range keys {
+		if _, ok := n.Labels[k]; ok {
+			return true
+		}
+	}
+	return false
 }
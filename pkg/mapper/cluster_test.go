@@ -0,0 +1,121 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func providerNode(name string, providerID string, labels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestMapNodes(t *testing.T) {
+	nodes := &corev1.NodeList{
+		Items: []corev1.Node{
+			providerNode("aks-general-2", "azure:///subscriptions/0000/virtualMachineScaleSets/aks-general/virtualMachines/2", map[string]string{"kubernetes.azure.com/cluster": "MC_rg_aks"}),
+			providerNode("aks-general-1", "azure:///subscriptions/0000/virtualMachineScaleSets/aks-general/virtualMachines/1", map[string]string{"kubernetes.azure.com/cluster": "MC_rg_aks"}),
+		},
+	}
+
+	cluster := MapNodes("aks-cluster", nodes, DefaultNodeLabels, nil, ClusterInfo{ServerVersion: "v1.18.14", APIGroups: []string{"apps"}})
+
+	assert.Equal(t, "aks-cluster", cluster.ID)
+	assert.Equal(t, "Cluster", cluster.Type)
+	data := cluster.Data.(map[string]interface{})
+	assert.Equal(t, "aks-cluster", data["clusterName"])
+	assert.Equal(t, "v1.18.14", data["serverVersion"])
+	assert.Equal(t, "azure", data["cloudProvider"])
+	assert.Equal(t, AKSDistribution, data["distribution"])
+}
+
+func TestCloudProvider(t *testing.T) {
+	tests := map[string]struct {
+		nodes    []corev1.Node
+		expected string
+	}{
+		"aws": {
+			nodes:    []corev1.Node{providerNode("ip-10-0-0-1", "aws:///eu-west-1a/i-0123456789", nil)},
+			expected: "aws",
+		},
+		"gce": {
+			nodes:    []corev1.Node{providerNode("gke-pool-1", "gce://project/europe-west1-b/gke-pool-1", nil)},
+			expected: "gce",
+		},
+		"first node with provider id": {
+			nodes: []corev1.Node{
+				providerNode("b", "aws:///eu-west-1a/i-0123456789", nil),
+				providerNode("a", "", nil),
+			},
+			expected: "aws",
+		},
+		"no provider id": {
+			nodes:    []corev1.Node{providerNode("minikube", "", nil)},
+			expected: "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cloudProvider(tt.nodes))
+		})
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	tests := map[string]struct {
+		nodes    []corev1.Node
+		info     ClusterInfo
+		expected string
+	}{
+		"OpenShift API groups": {
+			nodes:    []corev1.Node{providerNode("master-0", "aws:///eu-west-1a/i-0123456789", nil)},
+			info:     ClusterInfo{ServerVersion: "v1.19.0+8d12420", APIGroups: []string{"apps", "route.openshift.io"}},
+			expected: OpenShiftDistribution,
+		},
+		"EKS server version": {
+			info:     ClusterInfo{ServerVersion: "v1.18.9-eks-d1db3c"},
+			expected: EKSDistribution,
+		},
+		"EKS node labels": {
+			nodes:    []corev1.Node{providerNode("ip-10-0-0-1", "", map[string]string{"eks.amazonaws.com/nodegroup": "general"})},
+			info:     ClusterInfo{ServerVersion: "v1.18.9"},
+			expected: EKSDistribution,
+		},
+		"GKE server version": {
+			info:     ClusterInfo{ServerVersion: "v1.17.14-gke.1600"},
+			expected: GKEDistribution,
+		},
+		"GKE node labels": {
+			nodes:    []corev1.Node{providerNode("gke-pool-1", "", map[string]string{"cloud.google.com/gke-nodepool": "pool"})},
+			expected: GKEDistribution,
+		},
+		"AKS node labels": {
+			nodes:    []corev1.Node{providerNode("aks-general-1", "", map[string]string{"kubernetes.azure.com/role": "agent"})},
+			info:     ClusterInfo{ServerVersion: "v1.18.14"},
+			expected: AKSDistribution,
+		},
+		"k3s server version": {
+			info:     ClusterInfo{ServerVersion: "v1.20.4+k3s1"},
+			expected: K3sDistribution,
+		},
+		"k3s instance type": {
+			nodes:    []corev1.Node{providerNode("raspberry", "", map[string]string{"node.kubernetes.io/instance-type": "k3s"})},
+			expected: K3sDistribution,
+		},
+		"unknown": {
+			nodes:    []corev1.Node{providerNode("minikube", "", map[string]string{"kubernetes.io/hostname": "minikube"})},
+			info:     ClusterInfo{ServerVersion: "v1.20.2"},
+			expected: "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, distribution(tt.nodes, tt.info))
+		})
+	}
+}
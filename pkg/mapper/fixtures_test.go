@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// podOption modifies the pod created by pod
+type podOption func(p *unstructured.Unstructured)
+
+// pod returns a pod with a single container named app running image, modified by the given options
+func pod(namespace string, name string, image string, options ...podOption) *unstructured.Unstructured {
+	p := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "app", "image": image}},
+		},
+		"status": map[string]interface{}{},
+	}}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// ownedByReplicaSet adds a controller owner reference to the ReplicaSet with the given name
+func ownedByReplicaSet(name string) podOption {
+	return func(p *unstructured.Unstructured) {
+		p.Object["metadata"].(map[string]interface{})["ownerReferences"] = []interface{}{
+			map[string]interface{}{"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": name, "uid": "a1a2", "controller": true},
+		}
+	}
+}
+
+// withImageID adds a container status with the given image id
+func withImageID(imageID string) podOption {
+	return func(p *unstructured.Unstructured) {
+		image := p.Object["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["image"]
+		p.Object["status"].(map[string]interface{})["containerStatuses"] = []interface{}{
+			map[string]interface{}{"name": "app", "image": image, "imageID": imageID},
+		}
+	}
+}
+
+// inPhase sets the phase of the pod
+func inPhase(phase string) podOption {
+	return func(p *unstructured.Unstructured) {
+		p.Object["status"].(map[string]interface{})["phase"] = phase
+	}
+}
+
+// withResources sets the requests and limits of the container to the given cpu and memory
+func withResources(cpu string, memory string) podOption {
+	return func(p *unstructured.Unstructured) {
+		p.Object["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["resources"] = map[string]interface{}{
+			"requests": map[string]interface{}{"cpu": cpu, "memory": memory},
+			"limits":   map[string]interface{}{"cpu": cpu, "memory": memory},
+		}
+	}
+}
+
+// withInitContainer adds an init container requesting and limited to the given cpu and memory
+func withInitContainer(cpu string, memory string) podOption {
+	return func(p *unstructured.Unstructured) {
+		p.Object["spec"].(map[string]interface{})["initContainers"] = []interface{}{map[string]interface{}{
+			"name":  "init",
+			"image": "busybox",
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": cpu, "memory": memory},
+				"limits":   map[string]interface{}{"cpu": cpu, "memory": memory},
+			},
+		}}
+	}
+}
+
+// onNode schedules the pod on the given node
+func onNode(node string) podOption {
+	return func(p *unstructured.Unstructured) {
+		p.Object["spec"].(map[string]interface{})["nodeName"] = node
+	}
+}
@@ -0,0 +1,110 @@
+package mapper
+
+import (
+	"time"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/set"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// pressureConditions are the node conditions reporting a resource shortage if their status is true
+var pressureConditions = []corev1.NodeConditionType{
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+}
+
+// nodeAgeBuckets are the upper bounds of the node age buckets. Older nodes fall into the last bucket.
+var nodeAgeBuckets = []struct {
+	name   string
+	maxAge time.Duration
+}{
+	{name: "<1d", maxAge: 24 * time.Hour},
+	{name: "1d-7d", maxAge: 7 * 24 * time.Hour},
+	{name: "7d-30d", maxAge: 30 * 24 * time.Hour},
+	{name: "30d-90d", maxAge: 90 * 24 * time.Hour},
+}
+
+// lastNodeAgeBucket is the bucket of the nodes older than the upper bound of all other buckets
+const lastNodeAgeBucket string = ">=90d"
+
+// NodeHealth summarizes the conditions, scheduling state and age of nodes
+type NodeHealth struct {
+	// Ready and NotReady count the nodes by their Ready condition. Nodes with unknown readiness are not ready.
+	Ready    int `json:"ready"`
+	NotReady int `json:"notReady"`
+	// Cordoned counts the nodes marked as unschedulable
+	Cordoned int `json:"cordoned"`
+	// Pressure counts the nodes by active pressure condition, e.g. MemoryPressure
+	Pressure map[string]int `json:"pressure"`
+	// Taints are the distinct taints of the nodes in the format key[=value]:effect
+	Taints []string `json:"taints"`
+	// Age counts the nodes by age from the youngest to the oldest bucket
+	Age []NodeAgeBucket `json:"age"`
+}
+
+// NodeAgeBucket is the number of nodes in an age range, e.g. 1d-7d
+type NodeAgeBucket struct {
+	Name  string `json:"name"`
+	Nodes int    `json:"nodes"`
+}
+
+// aggregrateNodeHealth summarizes the health of the nodes. The age of the nodes is relative to now.
+func aggregrateNodeHealth(nodes []corev1.Node, now time.Time) NodeHealth {
+	health := NodeHealth{
+		Pressure: make(map[string]int),
+		Age:      make([]NodeAgeBucket, 0, len(nodeAgeBuckets)+1),
+	}
+	for _, b := range nodeAgeBuckets {
+		health.Age = append(health.Age, NodeAgeBucket{Name: b.name})
+	}
+	health.Age = append(health.Age, NodeAgeBucket{Name: lastNodeAgeBucket})
+	taints := set.NewStringSet()
+	for _, n := range nodes {
+		if nodeCondition(n, corev1.NodeReady) == corev1.ConditionTrue {
+			health.Ready++
+		} else {
+			health.NotReady++
+		}
+		if n.Spec.Unschedulable {
+			health.Cordoned++
+		}
+		for _, c := range pressureConditions {
+			if nodeCondition(n, c) == corev1.ConditionTrue {
+				health.Pressure[string(c)]++
+			}
+		}
+		for _, t := range n.Spec.Taints {
+			taints.Add(taintString(t))
+		}
+		health.Age[nodeAgeBucket(now.Sub(n.CreationTimestamp.Time))].Nodes++
+	}
+	health.Taints = taints.Items()
+	return health
+}
+
+// nodeCondition returns the status of the node condition with the given type or unknown if the node does not report it
+func nodeCondition(n corev1.Node, conditionType corev1.NodeConditionType) corev1.ConditionStatus {
+	for _, c := range n.Status.Conditions {
+		if c.Type == conditionType {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+func nodeAgeBucket(age time.Duration) int {
+	for i, b := range nodeAgeBuckets {
+		if age < b.maxAge {
+			return i
+		}
+	}
+	return len(nodeAgeBuckets)
+}
+
+func taintString(t corev1.Taint) string {
+	if t.Value == "" {
+		return t.Key + ":" + string(t.Effect)
+	}
+	return t.Key + "=" + t.Value + ":" + string(t.Effect)
+}
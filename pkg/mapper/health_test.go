@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAggregrateNodeHealth(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	node := func(age time.Duration, unschedulable bool, taints []corev1.Taint, conditions ...corev1.NodeCondition) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable, Taints: taints},
+			Status:     corev1.NodeStatus{Conditions: conditions},
+		}
+	}
+	ready := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}
+	notReady := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse}
+	unknown := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}
+	memoryPressure := corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue}
+	noDiskPressure := corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse}
+	gpu := corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := corev1.Taint{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule}
+	nodes := []corev1.Node{
+		node(time.Hour, false, nil, ready, noDiskPressure),
+		node(2*24*time.Hour, false, []corev1.Taint{gpu}, ready, memoryPressure),
+		node(10*24*time.Hour, false, []corev1.Taint{gpu}, notReady, memoryPressure),
+		node(100*24*time.Hour, true, []corev1.Taint{cordoned}, unknown),
+		node(365*24*time.Hour, false, nil),
+	}
+
+	health := aggregrateNodeHealth(nodes, now)
+
+	assert.Equal(t, NodeHealth{
+		Ready:    2,
+		NotReady: 3,
+		Cordoned: 1,
+		Pressure: map[string]int{"MemoryPressure": 2},
+		Taints:   []string{"node.kubernetes.io/unschedulable:NoSchedule", "nvidia.com/gpu=present:NoSchedule"},
+		Age: []NodeAgeBucket{
+			{Name: "<1d", Nodes: 1},
+			{Name: "1d-7d", Nodes: 1},
+			{Name: "7d-30d", Nodes: 1},
+			{Name: "30d-90d", Nodes: 0},
+			{Name: ">=90d", Nodes: 2},
+		},
+	}, health)
+}
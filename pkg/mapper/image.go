@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"strings"
+)
+
+// defaultRegistry is the registry of images referenced without registry
+const defaultRegistry string = "docker.io"
+
+// Image is a container image reference split into its parts
+type Image struct {
+	// Name is the image reference as written in the pod spec
+	Name       string `json:"name"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// ParseImage splits an image reference like registry.example.com:5000/team/app:1.0@sha256:abc into its parts.
+// References without registry belong to Docker Hub, where official images live in the library repository.
+func ParseImage(ref string) Image {
+	image := Image{Name: ref}
+	rest := ref
+	if i := strings.Index(rest, "@"); i >= 0 {
+		image.Digest = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.Contains(rest[i:], "/") {
+		image.Tag = rest[i+1:]
+		rest = rest[:i]
+	}
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		image.Registry = parts[0]
+		image.Repository = parts[1]
+	} else {
+		image.Registry = defaultRegistry
+		image.Repository = rest
+		if len(parts) == 1 {
+			image.Repository = "library/" + rest
+		}
+	}
+	if image.Tag == "" && image.Digest == "" {
+		image.Tag = "latest"
+	}
+	return image
+}
+
+// Reference returns the normalized image reference registry/repository[:tag][@digest]
+func (i Image) Reference() string {
+	ref := i.Registry + "/" + i.Repository
+	if i.Tag != "" {
+		ref += ":" + i.Tag
+	}
+	if i.Digest != "" {
+		ref += "@" + i.Digest
+	}
+	return ref
+}
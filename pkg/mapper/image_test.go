@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := map[string]struct {
+		input    string
+		expected Image
+	}{
+		"official image": {
+			input:    "nginx",
+			expected: Image{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		},
+		"docker hub image with tag": {
+			input:    "bitnami/redis:6.2",
+			expected: Image{Registry: "docker.io", Repository: "bitnami/redis", Tag: "6.2"},
+		},
+		"registry with port": {
+			input:    "registry.example.com:5000/team/app:1.0",
+			expected: Image{Registry: "registry.example.com:5000", Repository: "team/app", Tag: "1.0"},
+		},
+		"localhost": {
+			input:    "localhost/app",
+			expected: Image{Registry: "localhost", Repository: "app", Tag: "latest"},
+		},
+		"digest": {
+			input:    "gcr.io/distroless/static@sha256:abc",
+			expected: Image{Registry: "gcr.io", Repository: "distroless/static", Digest: "sha256:abc"},
+		},
+		"tag and digest": {
+			input:    "quay.io/coreos/etcd:v3.4@sha256:abc",
+			expected: Image{Registry: "quay.io", Repository: "coreos/etcd", Tag: "v3.4", Digest: "sha256:abc"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.expected.Name = tt.input
+
+			assert.Equal(t, tt.expected, ParseImage(tt.input))
+		})
+	}
+}
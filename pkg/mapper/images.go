@@ -0,0 +1,167 @@
+package mapper
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/set"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ImageType is the type of the image objects in the LDIF
+const ImageType string = "Image"
+
+// ImageUsage is the data of an image object. It lists where a container image runs.
+type ImageUsage struct {
+	Image
+	Namespaces []string            `json:"namespaces"`
+	Workloads  []WorkloadReference `json:"workloads"`
+}
+
+// WorkloadReference references the top-level controller of the pods running an image, or the pod itself if it has no controller
+type WorkloadReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type imageUsage struct {
+	image      Image
+	namespaces *set.String
+	pods       map[imagePod]bool
+}
+
+// imagePod is a pod running an image. The workload is resolved from the owner graph when the image objects are
+// built, because the controllers of the pod may be collected after the pod.
+type imagePod struct {
+	uid string
+	// workload is the workload referenced if the pod has no top-level controller in the owner graph
+	workload WorkloadReference
+}
+
+// ImageInventory collects the images of running containers from pods
+type ImageInventory struct {
+	images map[string]*imageUsage
+	owners *OwnerGraph
+}
+
+// NewImageInventory creates an empty ImageInventory. The owner graph resolves the top-level controllers
+// of the pods. Without owner graph the direct controllers are referenced.
+func NewImageInventory(owners *OwnerGraph) *ImageInventory {
+	return &ImageInventory{
+		images: make(map[string]*imageUsage),
+		owners: owners,
+	}
+}
+
+// Add adds the images of the containers of a pod to the inventory. Objects of other kinds are ignored.
+// The digest of an image is taken from the image id in the container status.
+func (inv *ImageInventory) Add(i *unstructured.Unstructured) error {
+	if i.GroupVersionKind().Group != "" || i.GetKind() != "Pod" {
+		return nil
+	}
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &pod); err != nil {
+		return err
+	}
+	imageIDs := make(map[string]string)
+	for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		imageIDs[s.Name] = s.ImageID
+	}
+	workload := WorkloadReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}
+	if inv.owners == nil {
+		if owner := controllerOf(pod.OwnerReferences); owner != nil {
+			workload = WorkloadReference{Kind: owner.Kind, Namespace: pod.Namespace, Name: owner.Name}
+		}
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		image := ParseImage(c.Image)
+		if image.Digest == "" {
+			image.Digest = digestOf(imageIDs[c.Name])
+		}
+		image.Name = image.Reference()
+		usage, ok := inv.images[image.Name]
+		if !ok {
+			usage = &imageUsage{
+				image:      image,
+				namespaces: set.NewStringSet(),
+				pods:       make(map[imagePod]bool),
+			}
+			inv.images[image.Name] = usage
+		}
+		usage.namespaces.Add(pod.Namespace)
+		usage.pods[imagePod{uid: string(pod.UID), workload: workload}] = true
+	}
+	return nil
+}
+
+// Objects returns an image object for every distinct image sorted by id. It must be called after all objects
+// were added to the owner graph.
+func (inv *ImageInventory) Objects() []KubernetesObject {
+	objects := make([]KubernetesObject, 0, len(inv.images))
+	for id, usage := range inv.images {
+		distinct := make(map[WorkloadReference]bool)
+		for p := range usage.pods {
+			distinct[inv.workload(p)] = true
+		}
+		workloads := make([]WorkloadReference, 0, len(distinct))
+		for w := range distinct {
+			workloads = append(workloads, w)
+		}
+		sort.Slice(workloads, func(i, j int) bool {
+			if workloads[i].Namespace != workloads[j].Namespace {
+				return workloads[i].Namespace < workloads[j].Namespace
+			}
+			if workloads[i].Kind != workloads[j].Kind {
+				return workloads[i].Kind < workloads[j].Kind
+			}
+			return workloads[i].Name < workloads[j].Name
+		})
+		objects = append(objects, KubernetesObject{
+			Type: ImageType,
+			ID:   id,
+			Data: ImageUsage{
+				Image:      usage.image,
+				Namespaces: usage.namespaces.Items(),
+				Workloads:  workloads,
+			},
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ID < objects[j].ID
+	})
+	return objects
+}
+
+// workload returns the top-level controller of the pod or the workload referenced without owner graph
+func (inv *ImageInventory) workload(p imagePod) WorkloadReference {
+	if inv.owners != nil {
+		if top := inv.owners.TopLevelController(p.uid); top != nil {
+			return WorkloadReference{Kind: top.Kind, Namespace: p.workload.Namespace, Name: top.Name}
+		}
+	}
+	return p.workload
+}
+
+// digestOf returns the digest of an image id like docker-pullable://nginx@sha256:abc or sha256:abc
+func digestOf(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	if strings.HasPrefix(imageID, "sha256:") {
+		return imageID
+	}
+	return ""
+}
+
+func controllerOf(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i, r := range refs {
+		if r.Controller != nil && *r.Controller {
+			return &refs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImageInventory(t *testing.T) {
+	inventory := NewImageInventory(nil)
+	objects := []*unstructured.Unstructured{
+		pod("default", "nginx-1", "nginx:1.19", ownedByReplicaSet("nginx-5d4f"), withImageID("docker-pullable://nginx@sha256:abc")),
+		pod("default", "nginx-2", "nginx:1.19", ownedByReplicaSet("nginx-5d4f"), withImageID("docker-pullable://nginx@sha256:abc")),
+		pod("web", "nginx", "docker.io/library/nginx:1.19", withImageID("docker-pullable://nginx@sha256:abc")),
+		pod("web", "redis", "redis:6", withImageID("sha256:def")),
+		{Object: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment"}},
+	}
+	for _, o := range objects {
+		assert.NoError(t, inventory.Add(o))
+	}
+
+	images := inventory.Objects()
+
+	assert.Equal(t, []KubernetesObject{
+		{
+			Type: ImageType,
+			ID:   "docker.io/library/nginx:1.19@sha256:abc",
+			Data: ImageUsage{
+				Image:      Image{Name: "docker.io/library/nginx:1.19@sha256:abc", Registry: "docker.io", Repository: "library/nginx", Tag: "1.19", Digest: "sha256:abc"},
+				Namespaces: []string{"default", "web"},
+				Workloads: []WorkloadReference{
+					{Kind: "ReplicaSet", Namespace: "default", Name: "nginx-5d4f"},
+					{Kind: "Pod", Namespace: "web", Name: "nginx"},
+				},
+			},
+		},
+		{
+			Type: ImageType,
+			ID:   "docker.io/library/redis:6@sha256:def",
+			Data: ImageUsage{
+				Image:      Image{Name: "docker.io/library/redis:6@sha256:def", Registry: "docker.io", Repository: "library/redis", Tag: "6", Digest: "sha256:def"},
+				Namespaces: []string{"web"},
+				Workloads:  []WorkloadReference{{Kind: "Pod", Namespace: "web", Name: "redis"}},
+			},
+		},
+	}, images)
+}
+
+func TestImageInventory_topLevelController(t *testing.T) {
+	deployment := owned("apps/v1", "Deployment", "nginx", "1", nil)
+	replicaSet := owned("apps/v1", "ReplicaSet", "nginx-5d4f", "2", deployment)
+	p := pod("default", "nginx-1", "nginx:1.19", ownedByReplicaSet("nginx-5d4f"), withImageID("docker-pullable://nginx@sha256:abc"))
+	p.SetUID("3")
+	p.SetOwnerReferences(owned("v1", "Pod", "nginx-1", "3", replicaSet).GetOwnerReferences())
+	owners := NewOwnerGraph()
+	for _, i := range []*unstructured.Unstructured{deployment, replicaSet, p} {
+		owners.Add(i)
+	}
+	inventory := NewImageInventory(owners)
+
+	assert.NoError(t, inventory.Add(p))
+
+	assert.Equal(t, []WorkloadReference{{Kind: "Deployment", Namespace: "default", Name: "nginx"}}, inventory.Objects()[0].Data.(ImageUsage).Workloads)
+}
+
+func TestImageInventory_controllerCollectedAfterPod(t *testing.T) {
+	deployment := owned("apps/v1", "Deployment", "nginx", "1", nil)
+	replicaSet := owned("apps/v1", "ReplicaSet", "nginx-5d4f", "2", deployment)
+	p := pod("default", "nginx-1", "nginx:1.19", ownedByReplicaSet("nginx-5d4f"))
+	p.SetUID("3")
+	p.SetOwnerReferences(owned("v1", "Pod", "nginx-1", "3", replicaSet).GetOwnerReferences())
+	owners := NewOwnerGraph()
+	inventory := NewImageInventory(owners)
+	owners.Add(p)
+	assert.NoError(t, inventory.Add(p))
+
+	owners.Add(replicaSet)
+	owners.Add(deployment)
+
+	assert.Equal(t, []WorkloadReference{{Kind: "Deployment", Namespace: "default", Name: "nginx"}}, inventory.Objects()[0].Data.(ImageUsage).Workloads)
+}
@@ -0,0 +1,156 @@
+package mapper
+
+import (
+	"sort"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/set"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NamespaceAggregateType is the type of the namespace aggregate objects in the LDIF
+const NamespaceAggregateType string = "NamespaceAggregate"
+
+// NamespaceAggregate aggregates the objects of a namespace
+type NamespaceAggregate struct {
+	Name        string            `json:"name"`
+	ClusterName string            `json:"clusterName"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Workloads counts the workloads and pods by kind
+	Workloads map[string]int `json:"workloads"`
+	// Requests and Limits are the sums of the effective resources of all running pods, which include
+	// the init containers like the resources of the Cluster object
+	Requests Resources `json:"requests"`
+	Limits   Resources `json:"limits"`
+	// Images are the distinct images of the running pods
+	Images         []string             `json:"images"`
+	ResourceQuotas []ResourceQuotaUsage `json:"resourceQuotas,omitempty"`
+	// LeanixApplication and Owner are resolved from the labels and annotations of the namespace
+	LeanixApplication string `json:"leanixApplication,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+}
+
+// ResourceQuotaUsage is the hard limit and the usage of the resources of a ResourceQuota
+type ResourceQuotaUsage struct {
+	Name string            `json:"name"`
+	Hard map[string]string `json:"hard"`
+	Used map[string]string `json:"used"`
+}
+
+type namespaceAggregate struct {
+	NamespaceAggregate
+	images *set.String
+}
+
+// NamespaceAggregator aggregates the collected objects by namespace
+type NamespaceAggregator struct {
+	namespaces map[string]*namespaceAggregate
+}
+
+// NewNamespaceAggregator creates an empty NamespaceAggregator
+func NewNamespaceAggregator() *NamespaceAggregator {
+	return &NamespaceAggregator{
+		namespaces: make(map[string]*namespaceAggregate),
+	}
+}
+
+// Add adds a Namespace or an object living in a namespace to the aggregates. Other cluster-scoped objects are ignored.
+func (a *NamespaceAggregator) Add(i *unstructured.Unstructured) error {
+	core := i.GroupVersionKind().Group == ""
+	if core && i.GetKind() == "Namespace" {
+		n := a.namespace(i.GetName())
+		n.Labels = i.GetLabels()
+		n.Annotations = i.GetAnnotations()
+		return nil
+	}
+	if i.GetNamespace() == "" {
+		return nil
+	}
+	n := a.namespace(i.GetNamespace())
+	if _, ok := workloadTemplatePaths[i.GetKind()]; ok {
+		n.Workloads[i.GetKind()]++
+	}
+	switch {
+	case core && i.GetKind() == "Pod":
+		n.Workloads[i.GetKind()]++
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &pod); err != nil {
+			return err
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			return nil
+		}
+		requests, limits := effectiveResources(pod.Spec)
+		n.Requests = addResources(n.Requests, requests)
+		n.Limits = addResources(n.Limits, limits)
+		for _, c := range pod.Spec.Containers {
+			n.images.Add(ParseImage(c.Image).Reference())
+		}
+	case core && i.GetKind() == "ResourceQuota":
+		var quota corev1.ResourceQuota
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &quota); err != nil {
+			return err
+		}
+		n.ResourceQuotas = append(n.ResourceQuotas, ResourceQuotaUsage{
+			Name: quota.Name,
+			Hard: quantities(quota.Status.Hard),
+			Used: quantities(quota.Status.Used),
+		})
+	}
+	return nil
+}
+
+// Objects returns an aggregate object for every namespace sorted by name.
+// The ownership of the namespaces is resolved if an ownership resolver is given.
+func (a *NamespaceAggregator) Objects(clusterName string, ownership *OwnershipResolver) []KubernetesObject {
+	names := make([]string, 0, len(a.namespaces))
+	for name := range a.namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	objects := make([]KubernetesObject, len(names))
+	for i, name := range names {
+		n := a.namespaces[name]
+		n.ClusterName = clusterName
+		n.Images = n.images.Items()
+		sort.Slice(n.ResourceQuotas, func(i, j int) bool {
+			return n.ResourceQuotas[i].Name < n.ResourceQuotas[j].Name
+		})
+		if ownership != nil {
+			o := ownership.ResolveNamespace(name)
+			n.LeanixApplication = o.Application
+			n.Owner = o.Owner
+		}
+		objects[i] = KubernetesObject{
+			Type: NamespaceAggregateType,
+			ID:   clusterName + "/" + name,
+			Data: &n.NamespaceAggregate,
+		}
+	}
+	return objects
+}
+
+func (a *NamespaceAggregator) namespace(name string) *namespaceAggregate {
+	n, ok := a.namespaces[name]
+	if !ok {
+		n = &namespaceAggregate{
+			NamespaceAggregate: NamespaceAggregate{
+				Name:      name,
+				Workloads: make(map[string]int),
+			},
+			images: set.NewStringSet(),
+		}
+		a.namespaces[name] = n
+	}
+	return n
+}
+
+func quantities(r corev1.ResourceList) map[string]string {
+	q := make(map[string]string, len(r))
+	for name, quantity := range r {
+		q[string(name)] = quantity.String()
+	}
+	return q
+}
@@ -0,0 +1,83 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNamespaceAggregator(t *testing.T) {
+	namespace := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name":        "shop",
+			"labels":      map[string]interface{}{"team": "checkout"},
+			"annotations": map[string]interface{}{"owner": "jane"},
+		},
+	}}
+	quota := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ResourceQuota",
+		"metadata":   map[string]interface{}{"name": "compute", "namespace": "shop"},
+		"status": map[string]interface{}{
+			"hard": map[string]interface{}{"requests.cpu": "4"},
+			"used": map[string]interface{}{"requests.cpu": "750m"},
+		},
+	}}
+	objects := []*unstructured.Unstructured{
+		namespace,
+		quota,
+		{Object: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]interface{}{"name": "web", "namespace": "shop"}}},
+		pod("shop", "web-1", "nginx:1.19", inPhase("Running"), withResources("250m", "64Mi")),
+		pod("shop", "web-2", "nginx:1.19", inPhase("Running"), withResources("500m", "64Mi")),
+		pod("shop", "job-1", "busybox", inPhase("Succeeded"), withResources("1", "1Gi")),
+		pod("blog", "wordpress", "wordpress", inPhase("Pending"), withResources("100m", "128Mi")),
+		{Object: map[string]interface{}{"apiVersion": "v1", "kind": "PersistentVolume", "metadata": map[string]interface{}{"name": "pv-1"}}},
+	}
+	a := NewNamespaceAggregator()
+	for _, o := range objects {
+		assert.NoError(t, a.Add(o))
+	}
+
+	aggregates := a.Objects("aks-cluster", nil)
+
+	assert.Len(t, aggregates, 2)
+	assert.Equal(t, "aks-cluster/blog", aggregates[0].ID)
+	assert.Equal(t, NamespaceAggregateType, aggregates[1].Type)
+	assert.Equal(t, "aks-cluster/shop", aggregates[1].ID)
+	assert.Equal(t, &NamespaceAggregate{
+		Name:        "shop",
+		ClusterName: "aks-cluster",
+		Labels:      map[string]string{"team": "checkout"},
+		Annotations: map[string]string{"owner": "jane"},
+		Workloads:   map[string]int{"Deployment": 1, "Pod": 3},
+		Requests:    Resources{CPUMillicores: 750, MemoryBytes: 128 * 1024 * 1024},
+		Limits:      Resources{CPUMillicores: 750, MemoryBytes: 128 * 1024 * 1024},
+		Images:      []string{"docker.io/library/nginx:1.19"},
+		ResourceQuotas: []ResourceQuotaUsage{
+			{Name: "compute", Hard: map[string]string{"requests.cpu": "4"}, Used: map[string]string{"requests.cpu": "750m"}},
+		},
+	}, aggregates[1].Data)
+}
+
+func TestNamespaceAggregator_effectiveResources(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		pod("shop", "web-1", "nginx:1.19", inPhase("Running"), onNode("node-1"), withResources("250m", "64Mi"), withInitContainer("1", "32Mi")),
+		pod("shop", "web-2", "nginx:1.19", inPhase("Running"), onNode("node-1"), withResources("500m", "64Mi")),
+	}
+	a := NewNamespaceAggregator()
+	pods := NewPodResources()
+	for _, o := range objects {
+		assert.NoError(t, a.Add(o))
+		assert.NoError(t, pods.Add(o))
+	}
+
+	aggregate := a.Objects("aks-cluster", nil)[0].Data.(*NamespaceAggregate)
+
+	requests, limits := pods.Node("node-1")
+	assert.Equal(t, Resources{CPUMillicores: 1500, MemoryBytes: 128 * 1024 * 1024}, aggregate.Requests)
+	assert.Equal(t, requests, aggregate.Requests, "equals the requests of the Cluster object")
+	assert.Equal(t, limits, aggregate.Limits, "equals the limits of the Cluster object")
+}
@@ -2,6 +2,8 @@ package mapper
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/leanix/leanix-k8s-connector/pkg/set"
@@ -9,11 +11,94 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-func aggregrateNodes(nodes *corev1.NodeList) (map[string]interface{}, error) {
+// Node label fields that can be mapped to custom label keys
+const (
+	ZoneLabelField         string = "zone"
+	RegionLabelField       string = "region"
+	InstanceTypeLabelField string = "instanceType"
+	NodePoolLabelField     string = "nodePool"
+)
+
+// NodeLabels are the label keys the topology and the instance type of the nodes are read from in order of precedence
+type NodeLabels struct {
+	Zone         []string
+	Region       []string
+	InstanceType []string
+	NodePool     []string
+}
+
+// DefaultNodeLabels prefer the GA labels and fall back to the deprecated beta labels
+var DefaultNodeLabels = NodeLabels{
+	Zone:         []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"},
+	Region:       []string{"topology.kubernetes.io/region", "failure-domain.beta.kubernetes.io/region"},
+	InstanceType: []string{"node.kubernetes.io/instance-type", "beta.kubernetes.io/instance-type"},
+	NodePool:     []string{"agentpool", "eks.amazonaws.com/nodegroup", "cloud.google.com/gke-nodepool"},
+}
+
+// NodePool aggregates the nodes of a node pool
+type NodePool struct {
+	// Name is the value of the node pool label. Nodes without node pool label form a pool with an empty name.
+	Name              string   `json:"name"`
+	NumberNodes       int      `json:"numberNodes"`
+	NodeTypes         []string `json:"nodeTypes"`
+	AvailabilityZones []string `json:"availabilityZones"`
+	MemoryCapacityGB  float64  `json:"memoryCapacityGB"`
+	CPUCapacity       float64  `json:"cpuCapacity"`
+	KubeletVersion    []string `json:"kubeletVersion"`
+}
+
+// ParseNodeLabels parses a label key mapping in the format field=key, e.g. zone=example.com/zone.
+// The mapped keys take precedence over the default node labels. Every item may contain multiple comma separated entries.
+func ParseNodeLabels(entries []string) (NodeLabels, error) {
+	custom := NodeLabels{}
+	for _, item := range entries {
+		for _, e := range strings.Split(item, ",") {
+			e = strings.TrimSpace(e)
+			if e == "" {
+				continue
+			}
+			parts := strings.SplitN(e, "=", 2)
+			if len(parts) != 2 || parts[1] == "" {
+				return NodeLabels{}, fmt.Errorf("invalid node label mapping %q: expected field=key", e)
+			}
+			switch parts[0] {
+			case ZoneLabelField:
+				custom.Zone = append(custom.Zone, parts[1])
+			case RegionLabelField:
+				custom.Region = append(custom.Region, parts[1])
+			case InstanceTypeLabelField:
+				custom.InstanceType = append(custom.InstanceType, parts[1])
+			case NodePoolLabelField:
+				custom.NodePool = append(custom.NodePool, parts[1])
+			default:
+				return NodeLabels{}, fmt.Errorf("invalid node label mapping %q: unsupported field %s (%s, %s, %s, %s)", e, parts[0], ZoneLabelField, RegionLabelField, InstanceTypeLabelField, NodePoolLabelField)
+			}
+		}
+	}
+	return NodeLabels{
+		Zone:         append(custom.Zone, DefaultNodeLabels.Zone...),
+		Region:       append(custom.Region, DefaultNodeLabels.Region...),
+		InstanceType: append(custom.InstanceType, DefaultNodeLabels.InstanceType...),
+		NodePool:     append(custom.NodePool, DefaultNodeLabels.NodePool...),
+	}, nil
+}
+
+// labelValue returns the value of the first of the given label keys set on the node
+func labelValue(n corev1.Node, keys []string) string {
+	for _, k := range keys {
+		if v := n.Labels[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// aggregrateNodes aggregates the nodes and the resources of the pods scheduled on them. The pod resources are optional.
+func aggregrateNodes(nodes *corev1.NodeList, nodeLabels NodeLabels, pods *PodResources) map[string]interface{} {
 	nodeAggregate := make(map[string]interface{})
-	items := nodes.Items
+	items := sortedNodes(nodes.Items)
 	if len(items) == 0 {
-		return nodeAggregate, nil
+		return nodeAggregate
 	}
 	availabilityZones := set.NewStringSet()
 	nodeTypes := set.NewStringSet()
@@ -23,12 +108,20 @@ func aggregrateNodes(nodes *corev1.NodeList) (map[string]interface{}, error) {
 	kubeletVersion := set.NewStringSet()
 	operatingSystem := set.NewStringSet()
 	osImage := set.NewStringSet()
+	dataCenter := ""
 	firstCreatedNode := items[0].ObjectMeta.CreationTimestamp
 	lastCreatedNode := items[0].ObjectMeta.CreationTimestamp
 
 	for _, n := range items {
-		availabilityZones.Add(n.Labels["failure-domain.beta.kubernetes.io/zone"])
-		nodeTypes.Add(n.Labels["beta.kubernetes.io/instance-type"])
+		if zone := labelValue(n, nodeLabels.Zone); zone != "" {
+			availabilityZones.Add(zone)
+		}
+		if nodeType := labelValue(n, nodeLabels.InstanceType); nodeType != "" {
+			nodeTypes.Add(nodeType)
+		}
+		if dataCenter == "" {
+			dataCenter = labelValue(n, nodeLabels.Region)
+		}
 		architectures.Add(n.Status.NodeInfo.Architecture)
 		containerRuntimeVersion.Add(n.Status.NodeInfo.ContainerRuntimeVersion)
 		kernelVersion.Add(n.Status.NodeInfo.KernelVersion)
@@ -43,16 +136,11 @@ func aggregrateNodes(nodes *corev1.NodeList) (map[string]interface{}, error) {
 			lastCreatedNode = nodeCreatedTimestamp
 		}
 	}
-	memory, err := aggregrateMemoryCapacity(&items)
-	if err != nil {
-		return nil, err
-	}
-	cpus, err := aggregrateCPUCapacity(&items)
-	if err != nil {
-		return nil, err
-	}
+	memory := aggregrateMemoryCapacity(&items)
+	cpus := aggregrateCPUCapacity(&items)
+	nodePools := aggregrateNodePools(items, nodeLabels)
 	nodeAggregate["availabilityZones"] = availabilityZones.Items()
-	nodeAggregate["dataCenter"] = items[0].Labels["failure-domain.beta.kubernetes.io/region"]
+	nodeAggregate["dataCenter"] = dataCenter
 	nodeAggregate["nodeTypes"] = nodeTypes.Items()
 	nodeAggregate["numberNodes"] = len(items)
 	nodeAggregate["memoryCapacityGB"] = memory
@@ -66,35 +154,80 @@ func aggregrateNodes(nodes *corev1.NodeList) (map[string]interface{}, error) {
 	nodeAggregate["labels"] = labelSet(&items)
 	nodeAggregate["firstCreatedNode"] = firstCreatedNode.UTC().Format(time.RFC3339)
 	nodeAggregate["lastCreatedNode"] = lastCreatedNode.UTC().Format(time.RFC3339)
-	return nodeAggregate, nil
+	nodeAggregate["nodePools"] = nodePools
+	nodeAggregate["resources"] = aggregrateNodeResources(items, pods)
+	nodeAggregate["health"] = aggregrateNodeHealth(items, time.Now())
+	return nodeAggregate
+}
+
+// aggregrateNodePools groups the nodes by their node pool label and aggregates every pool. The pools are sorted by name.
+func aggregrateNodePools(nodes []corev1.Node, nodeLabels NodeLabels) []NodePool {
+	poolNodes := make(map[string][]corev1.Node)
+	for _, n := range nodes {
+		name := labelValue(n, nodeLabels.NodePool)
+		poolNodes[name] = append(poolNodes[name], n)
+	}
+	names := make([]string, 0, len(poolNodes))
+	for name := range poolNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pools := make([]NodePool, 0, len(names))
+	for _, name := range names {
+		items := poolNodes[name]
+		nodeTypes := set.NewStringSet()
+		availabilityZones := set.NewStringSet()
+		kubeletVersion := set.NewStringSet()
+		for _, n := range items {
+			if nodeType := labelValue(n, nodeLabels.InstanceType); nodeType != "" {
+				nodeTypes.Add(nodeType)
+			}
+			if zone := labelValue(n, nodeLabels.Zone); zone != "" {
+				availabilityZones.Add(zone)
+			}
+			kubeletVersion.Add(n.Status.NodeInfo.KubeletVersion)
+		}
+		pools = append(pools, NodePool{
+			Name:              name,
+			NumberNodes:       len(items),
+			NodeTypes:         nodeTypes.Items(),
+			AvailabilityZones: availabilityZones.Items(),
+			MemoryCapacityGB:  aggregrateMemoryCapacity(&items),
+			CPUCapacity:       aggregrateCPUCapacity(&items),
+			KubeletVersion:    kubeletVersion.Items(),
+		})
+	}
+	return pools
 }
 
-func aggregrateMemoryCapacity(nodes *[]corev1.Node) (float64, error) {
+// sortedNodes returns a copy of the nodes sorted by name, so the aggregate does not depend on the order of the node list
+func sortedNodes(nodes []corev1.Node) []corev1.Node {
+	sorted := make([]corev1.Node, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func aggregrateMemoryCapacity(nodes *[]corev1.Node) float64 {
 	var memoryCapacityGB float64
 	for _, n := range *nodes {
-		// The Memory() call returns the memory as resource.Quantity. 'Quantity is a fixed-point representation of a number.'
-		// In order to calculate the memory capacity of all nodes, we get the bytes as int64 (hoping it does not exceed the int64 limit...).
 		// We convert the bytes here to GiB to make sure that we do not exceed the limit of float64. This introduces a rounding error,
 		// which we accept, because a precise value is not of interest for the user output.
-		b, ok := n.Status.Capacity.Memory().AsInt64()
-		if !ok {
-			return 0, fmt.Errorf("Failed to get memory quantity as type int64")
-		}
-		memoryCapacityGB = memoryCapacityGB + byteToGiB(b)
+		memoryCapacityGB = memoryCapacityGB + byteToGiB(n.Status.Capacity.Memory().Value())
 	}
-	return memoryCapacityGB, nil
+	return memoryCapacityGB
 }
 
-func aggregrateCPUCapacity(nodes *[]corev1.Node) (int64, error) {
-	var cpuCapacity int64
+// aggregrateCPUCapacity returns the sum of the cpu capacity of the nodes in cores. Fractional cores are kept.
+func aggregrateCPUCapacity(nodes *[]corev1.Node) float64 {
+	var cpuMillicores int64
 	for _, n := range *nodes {
-		cores, ok := n.Status.Capacity.Cpu().AsInt64()
-		if !ok {
-			return 0, fmt.Errorf("Failed to get cpu quantity as type int64")
-		}
-		cpuCapacity = cpuCapacity + cores
+		cpuMillicores = cpuMillicores + n.Status.Capacity.Cpu().MilliValue()
 	}
-	return cpuCapacity, nil
+	return float64(cpuMillicores) / 1000
 }
 
 func byteToGiB(b int64) float64 {
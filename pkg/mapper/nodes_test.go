@@ -83,25 +83,122 @@ func TestAggregateNodes(t *testing.T) {
 		"beta.kubernetes.io/instance-type":         []string{"Standard_D2s_v3", "Standard_D8s_v3"},
 	}
 
-	nodeAggregate, err := aggregrateNodes(nodes)
-	assert.NoError(t, err)
+	nodeAggregate := aggregrateNodes(nodes, DefaultNodeLabels, nil)
 
 	assert.Equal(t, "westeurope", nodeAggregate["dataCenter"])
 	assert.Equal(t, "2019-01-12T08:55:20Z", nodeAggregate["firstCreatedNode"])
 	assert.Equal(t, "2019-01-18T08:55:20Z", nodeAggregate["lastCreatedNode"])
-	assert.ElementsMatch(t, []string{"1", "2"}, nodeAggregate["availabilityZones"])
-	assert.ElementsMatch(t, []string{"Standard_D2s_v3", "Standard_D8s_v3"}, nodeAggregate["nodeTypes"])
+	assert.Equal(t, []string{"1", "2"}, nodeAggregate["availabilityZones"])
+	assert.Equal(t, []string{"Standard_D2s_v3", "Standard_D8s_v3"}, nodeAggregate["nodeTypes"])
 	assert.Equal(t, 2, nodeAggregate["numberNodes"])
 	assert.Equal(t, float64(2), nodeAggregate["memoryCapacityGB"])
-	assert.Equal(t, int64(2), nodeAggregate["cpuCapacity"])
-	assert.ElementsMatch(t, []string{"amd64"}, nodeAggregate["architecture"])
-	assert.ElementsMatch(t, []string{"docker://3.0.1"}, nodeAggregate["containerRuntimeVersion"])
-	assert.ElementsMatch(t, []string{"4.15.0-1035-azure"}, nodeAggregate["kernelVersion"])
-	assert.ElementsMatch(t, []string{"v1.11.5"}, nodeAggregate["kubeletVersion"])
-	assert.ElementsMatch(t, []string{"linux"}, nodeAggregate["operatingSystem"])
-	assert.ElementsMatch(t, []string{"Ubuntu 16.04.5 LTS"}, nodeAggregate["osImage"])
-	for k, v := range expectedLabelAggregate {
-		assert.ElementsMatch(t, v, nodeAggregate["labels"].(map[string][]string)[k])
+	assert.Equal(t, float64(2), nodeAggregate["cpuCapacity"])
+	assert.Equal(t, []string{"amd64"}, nodeAggregate["architecture"])
+	assert.Equal(t, []string{"docker://3.0.1"}, nodeAggregate["containerRuntimeVersion"])
+	assert.Equal(t, []string{"4.15.0-1035-azure"}, nodeAggregate["kernelVersion"])
+	assert.Equal(t, []string{"v1.11.5"}, nodeAggregate["kubeletVersion"])
+	assert.Equal(t, []string{"linux"}, nodeAggregate["operatingSystem"])
+	assert.Equal(t, []string{"Ubuntu 16.04.5 LTS"}, nodeAggregate["osImage"])
+	assert.Equal(t, expectedLabelAggregate, nodeAggregate["labels"])
+
+	reversed := &corev1.NodeList{
+		Items: []corev1.Node{nodes.Items[1], nodes.Items[0]},
+	}
+	reversedAggregate := aggregrateNodes(reversed, DefaultNodeLabels, nil)
+	assert.Equal(t, nodeAggregate, reversedAggregate)
+}
+
+func TestAggregateNodes_nodeLabels(t *testing.T) {
+	nodeLabels, err := ParseNodeLabels([]string{"zone=example.com/zone"})
+	assert.NoError(t, err)
+	nodes := &corev1.NodeList{
+		Items: []corev1.Node{
+			corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ga",
+					Labels: map[string]string{
+						"topology.kubernetes.io/region":            "eu-west-1",
+						"failure-domain.beta.kubernetes.io/region": "deprecated",
+						"topology.kubernetes.io/zone":              "eu-west-1a",
+						"failure-domain.beta.kubernetes.io/zone":   "deprecated",
+						"node.kubernetes.io/instance-type":         "m5.large",
+						"beta.kubernetes.io/instance-type":         "deprecated",
+					},
+				},
+			},
+			corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "beta",
+					Labels: map[string]string{
+						"failure-domain.beta.kubernetes.io/region": "eu-west-1",
+						"failure-domain.beta.kubernetes.io/zone":   "eu-west-1b",
+						"beta.kubernetes.io/instance-type":         "m5.xlarge",
+					},
+				},
+			},
+			corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "custom",
+					Labels: map[string]string{
+						"example.com/zone":            "rack-1",
+						"topology.kubernetes.io/zone": "eu-west-1c",
+					},
+				},
+			},
+		},
+	}
+
+	nodeAggregate := aggregrateNodes(nodes, nodeLabels, nil)
+
+	assert.Equal(t, "eu-west-1", nodeAggregate["dataCenter"])
+	assert.Equal(t, []string{"eu-west-1a", "eu-west-1b", "rack-1"}, nodeAggregate["availabilityZones"])
+	assert.Equal(t, []string{"m5.large", "m5.xlarge"}, nodeAggregate["nodeTypes"])
+}
+
+func TestAggregrateNodePools(t *testing.T) {
+	node := func(name string, labels map[string]string, cpu string, memory string, kubelet string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: kubelet},
+			},
+		}
+	}
+	nodeLabels, err := ParseNodeLabels([]string{"nodePool=example.com/pool"})
+	assert.NoError(t, err)
+	nodes := []corev1.Node{
+		node("aks-general-1", map[string]string{"agentpool": "general", "topology.kubernetes.io/zone": "1", "node.kubernetes.io/instance-type": "Standard_D4s_v3"}, "4", "16Gi", "v1.18.10"),
+		node("aks-general-2", map[string]string{"agentpool": "general", "topology.kubernetes.io/zone": "2", "node.kubernetes.io/instance-type": "Standard_D4s_v3"}, "4", "16Gi", "v1.18.14"),
+		node("aks-gpu-1", map[string]string{"agentpool": "gpu", "topology.kubernetes.io/zone": "1", "node.kubernetes.io/instance-type": "Standard_NC6"}, "6", "56Gi", "v1.18.14"),
+		node("custom-1", map[string]string{"example.com/pool": "batch", "agentpool": "ignored"}, "2", "8Gi", "v1.18.14"),
+		node("unpooled", map[string]string{}, "1", "1Gi", "v1.18.14"),
+	}
+
+	pools := aggregrateNodePools(nodes, nodeLabels)
+
+	assert.Equal(t, []NodePool{
+		{Name: "", NumberNodes: 1, NodeTypes: []string{}, AvailabilityZones: []string{}, MemoryCapacityGB: 1, CPUCapacity: 1, KubeletVersion: []string{"v1.18.14"}},
+		{Name: "batch", NumberNodes: 1, NodeTypes: []string{}, AvailabilityZones: []string{}, MemoryCapacityGB: 8, CPUCapacity: 2, KubeletVersion: []string{"v1.18.14"}},
+		{Name: "general", NumberNodes: 2, NodeTypes: []string{"Standard_D4s_v3"}, AvailabilityZones: []string{"1", "2"}, MemoryCapacityGB: 32, CPUCapacity: 8, KubeletVersion: []string{"v1.18.10", "v1.18.14"}},
+		{Name: "gpu", NumberNodes: 1, NodeTypes: []string{"Standard_NC6"}, AvailabilityZones: []string{"1"}, MemoryCapacityGB: 56, CPUCapacity: 6, KubeletVersion: []string{"v1.18.14"}},
+	}, pools)
+}
+
+func TestParseNodeLabels(t *testing.T) {
+	nodeLabels, err := ParseNodeLabels([]string{"region=example.com/region, instanceType=example.com/size", "instanceType=example.com/flavor"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultNodeLabels.Zone, nodeLabels.Zone)
+	assert.Equal(t, append([]string{"example.com/region"}, DefaultNodeLabels.Region...), nodeLabels.Region)
+	assert.Equal(t, append([]string{"example.com/size", "example.com/flavor"}, DefaultNodeLabels.InstanceType...), nodeLabels.InstanceType)
+
+	for _, input := range []string{"zone", "zone=", "rack=example.com/rack"} {
+		_, err := ParseNodeLabels([]string{input})
+		assert.Error(t, err, input)
 	}
 }
 
@@ -168,12 +265,23 @@ func TestAggregrateMemoryCapacity(t *testing.T) {
 			},
 			expected: 1.5,
 		},
+		"fractional quantity": {
+			input: []corev1.Node{
+				corev1.Node{
+					Status: corev1.NodeStatus{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("1.5Gi"),
+						},
+					},
+				},
+			},
+			expected: 1.5,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			memory, err := aggregrateMemoryCapacity(&test.input)
-			assert.NoError(t, err)
+			memory := aggregrateMemoryCapacity(&test.input)
 			assert.Equal(t, test.expected, memory)
 		})
 	}
@@ -190,7 +298,7 @@ func TestAggregrateCPUCapacity(t *testing.T) {
 	}
 	tests := map[string]struct {
 		input    []corev1.Node
-		expected int64
+		expected float64
 	}{
 		"single 1 core node": {
 			input: []corev1.Node{
@@ -242,12 +350,23 @@ func TestAggregrateCPUCapacity(t *testing.T) {
 			},
 			expected: 3,
 		},
+		"fractional cores": {
+			input: []corev1.Node{
+				corev1.Node{
+					Status: corev1.NodeStatus{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("3500m"),
+						},
+					},
+				},
+			},
+			expected: 3.5,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			cores, err := aggregrateCPUCapacity(&test.input)
-			assert.NoError(t, err)
+			cores := aggregrateCPUCapacity(&test.input)
 			assert.Equal(t, test.expected, cores)
 		})
 	}
@@ -0,0 +1,103 @@
+package mapper
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ControllerField is the data field holding the top-level controller of raw objects
+const ControllerField string = "controller"
+
+// ObjectReference identifies an object by kind, name and uid
+type ObjectReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+}
+
+type ownerNode struct {
+	ref        ObjectReference
+	controller *ObjectReference
+}
+
+// OwnerGraph resolves the controller owner references between the collected objects
+type OwnerGraph struct {
+	nodes map[string]ownerNode
+}
+
+// NewOwnerGraph creates an empty OwnerGraph
+func NewOwnerGraph() *OwnerGraph {
+	return &OwnerGraph{
+		nodes: make(map[string]ownerNode),
+	}
+}
+
+// Add adds an object and its controller owner reference to the graph
+func (g *OwnerGraph) Add(i *unstructured.Unstructured) {
+	n := ownerNode{
+		ref: ObjectReference{Kind: i.GetKind(), Name: i.GetName(), UID: string(i.GetUID())},
+	}
+	if c := controllerOf(i.GetOwnerReferences()); c != nil {
+		n.controller = &ObjectReference{Kind: c.Kind, Name: c.Name, UID: string(c.UID)}
+	}
+	g.nodes[n.ref.UID] = n
+}
+
+// ControllerChain follows the controller owner references of the object with the given uid and returns
+// the controllers in order, e.g. the ReplicaSet and the Deployment of a Pod. Controllers that were
+// not collected end the chain.
+func (g *OwnerGraph) ControllerChain(uid string) []ObjectReference {
+	n, ok := g.nodes[uid]
+	if !ok {
+		return nil
+	}
+	chain := make([]ObjectReference, 0)
+	// the chain can not be longer than the number of objects, this guards against cyclic references
+	for steps := 0; n.controller != nil && steps < len(g.nodes); steps++ {
+		chain = append(chain, *n.controller)
+		if n, ok = g.nodes[n.controller.UID]; !ok {
+			break
+		}
+	}
+	return chain
+}
+
+// TopLevelController returns the last controller in the controller chain of the object with the given uid,
+// e.g. the Deployment of a Pod owned by a ReplicaSet. Nil is returned if the object has no controller.
+func (g *OwnerGraph) TopLevelController(uid string) *ObjectReference {
+	chain := g.ControllerChain(uid)
+	if len(chain) == 0 {
+		return nil
+	}
+	return &chain[len(chain)-1]
+}
+
+// Collapsible returns true for pods and replicasets that have a collected top-level controller,
+// so they can be omitted in favour of their owning workload
+func (g *OwnerGraph) Collapsible(i *unstructured.Unstructured) bool {
+	gk := i.GroupVersionKind().GroupKind()
+	isPod := gk.Group == "" && gk.Kind == "Pod"
+	isReplicaSet := (gk.Group == "apps" || gk.Group == "extensions") && gk.Kind == "ReplicaSet"
+	if !isPod && !isReplicaSet {
+		return false
+	}
+	top := g.TopLevelController(string(i.GetUID()))
+	if top == nil {
+		return false
+	}
+	_, ok := g.nodes[top.UID]
+	return ok
+}
+
+// SetController attaches the top-level controller to the data of the object
+func SetController(o *KubernetesObject, controller *ObjectReference) {
+	switch d := o.Data.(type) {
+	case *Workload:
+		d.Controller = controller
+	case map[string]interface{}:
+		d[ControllerField] = map[string]interface{}{
+			"kind": controller.Kind,
+			"name": controller.Name,
+			"uid":  controller.UID,
+		}
+	}
+}
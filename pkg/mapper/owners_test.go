@@ -0,0 +1,74 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func owned(apiVersion string, kind string, name string, uid string, owner *unstructured.Unstructured) *unstructured.Unstructured {
+	i := &unstructured.Unstructured{}
+	i.SetAPIVersion(apiVersion)
+	i.SetKind(kind)
+	i.SetName(name)
+	i.SetUID(types.UID(uid))
+	if owner != nil {
+		controller := true
+		i.SetOwnerReferences([]metav1.OwnerReference{
+			{APIVersion: owner.GetAPIVersion(), Kind: owner.GetKind(), Name: owner.GetName(), UID: owner.GetUID(), Controller: &controller},
+		})
+	}
+	return i
+}
+
+func TestOwnerGraph(t *testing.T) {
+	deployment := owned("apps/v1", "Deployment", "nginx", "1", nil)
+	replicaSet := owned("apps/v1", "ReplicaSet", "nginx-5d4f", "2", deployment)
+	pod := owned("v1", "Pod", "nginx-5d4f-x7k2", "3", replicaSet)
+	orphanedReplicaSet := owned("apps/v1", "ReplicaSet", "redis-8a9b", "4", owned("apps/v1", "Deployment", "redis", "5", nil))
+	standalonePod := owned("v1", "Pod", "debug", "6", nil)
+	g := NewOwnerGraph()
+	for _, i := range []*unstructured.Unstructured{deployment, replicaSet, pod, orphanedReplicaSet, standalonePod} {
+		g.Add(i)
+	}
+
+	deploymentRef := &ObjectReference{Kind: "Deployment", Name: "nginx", UID: "1"}
+	assert.Equal(t, deploymentRef, g.TopLevelController("3"))
+	assert.Equal(t, deploymentRef, g.TopLevelController("2"))
+	assert.Nil(t, g.TopLevelController("1"))
+	assert.Equal(t, &ObjectReference{Kind: "Deployment", Name: "redis", UID: "5"}, g.TopLevelController("4"))
+	assert.Nil(t, g.TopLevelController("6"))
+	assert.Nil(t, g.TopLevelController("unknown"))
+
+	assert.True(t, g.Collapsible(pod))
+	assert.True(t, g.Collapsible(replicaSet))
+	assert.False(t, g.Collapsible(deployment))
+	assert.False(t, g.Collapsible(orphanedReplicaSet))
+	assert.False(t, g.Collapsible(standalonePod))
+}
+
+func TestOwnerGraph_cycle(t *testing.T) {
+	a := owned("v1", "Pod", "a", "1", nil)
+	b := owned("v1", "Pod", "b", "2", a)
+	a.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Pod", Name: "b", UID: "2", Controller: b.GetOwnerReferences()[0].Controller}})
+	g := NewOwnerGraph()
+	g.Add(a)
+	g.Add(b)
+
+	assert.NotNil(t, g.TopLevelController("1"))
+}
+
+func TestSetController(t *testing.T) {
+	controller := &ObjectReference{Kind: "Deployment", Name: "nginx", UID: "1"}
+	raw := KubernetesObject{Data: map[string]interface{}{}}
+	workload := KubernetesObject{Data: &Workload{}}
+
+	SetController(&raw, controller)
+	SetController(&workload, controller)
+
+	assert.Equal(t, map[string]interface{}{"kind": "Deployment", "name": "nginx", "uid": "1"}, raw.Data.(map[string]interface{})[ControllerField])
+	assert.Equal(t, controller, workload.Data.(*Workload).Controller)
+}
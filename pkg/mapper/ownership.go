@@ -0,0 +1,163 @@
+package mapper
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// LabelSource reads the value of a label
+	LabelSource string = "label"
+	// AnnotationSource reads the value of an annotation
+	AnnotationSource string = "annotation"
+)
+
+const (
+	// ApplicationField is the data field holding the LeanIX application of raw objects
+	ApplicationField string = "leanixApplication"
+	// OwnerField is the data field holding the owner of raw objects
+	OwnerField string = "owner"
+)
+
+// DefaultApplicationSources are the sources of the LeanIX application when no sources are configured
+var DefaultApplicationSources = []string{
+	"annotation:leanix.net/factsheet-id",
+	"label:app.kubernetes.io/part-of",
+}
+
+// OwnershipSource reads the value of a label or annotation
+type OwnershipSource struct {
+	Type string
+	Key  string
+}
+
+// ParseOwnershipSource parses a source in the format label:key or annotation:key
+func ParseOwnershipSource(s string) (OwnershipSource, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	if len(parts) != 2 || parts[1] == "" || (parts[0] != LabelSource && parts[0] != AnnotationSource) {
+		return OwnershipSource{}, fmt.Errorf("invalid ownership source %q: expected %s:key or %s:key", s, LabelSource, AnnotationSource)
+	}
+	return OwnershipSource{Type: parts[0], Key: parts[1]}, nil
+}
+
+// ParseOwnershipSources parses a list of sources. Every item may contain multiple comma separated sources.
+func ParseOwnershipSources(entries []string) ([]OwnershipSource, error) {
+	sources := make([]OwnershipSource, 0)
+	for _, item := range entries {
+		for _, s := range strings.Split(item, ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			source, err := ParseOwnershipSource(s)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// OwnershipMapping lists the sources of the LeanIX application and the owner in order of precedence
+type OwnershipMapping struct {
+	Application []OwnershipSource
+	Owner       []OwnershipSource
+}
+
+// Ownership links an object to a LeanIX application and an owner
+type Ownership struct {
+	Application string
+	Owner       string
+}
+
+// OwnershipResolver resolves the ownership of objects from their labels and annotations.
+// Values missing on an object are taken from its controllers and finally from its namespace.
+type OwnershipResolver struct {
+	mapping    OwnershipMapping
+	owners     *OwnerGraph
+	objects    map[string]Ownership
+	namespaces map[string]Ownership
+}
+
+// NewOwnershipResolver creates a resolver that follows the controller chains of the owner graph
+func NewOwnershipResolver(mapping OwnershipMapping, owners *OwnerGraph) *OwnershipResolver {
+	return &OwnershipResolver{
+		mapping:    mapping,
+		owners:     owners,
+		objects:    make(map[string]Ownership),
+		namespaces: make(map[string]Ownership),
+	}
+}
+
+// Add reads the ownership of an object from its own labels and annotations
+func (r *OwnershipResolver) Add(i *unstructured.Unstructured) {
+	o := Ownership{
+		Application: lookup(i, r.mapping.Application),
+		Owner:       lookup(i, r.mapping.Owner),
+	}
+	if o == (Ownership{}) {
+		return
+	}
+	r.objects[string(i.GetUID())] = o
+	if i.GetKind() == "Namespace" && i.GroupVersionKind().Group == "" {
+		r.namespaces[i.GetName()] = o
+	}
+}
+
+// Resolve returns the ownership of an object. Every field is taken from the object itself,
+// the first controller in its controller chain or its namespace that provides a value.
+func (r *OwnershipResolver) Resolve(i *unstructured.Unstructured) Ownership {
+	candidates := []Ownership{r.objects[string(i.GetUID())]}
+	for _, c := range r.owners.ControllerChain(string(i.GetUID())) {
+		candidates = append(candidates, r.objects[c.UID])
+	}
+	candidates = append(candidates, r.namespaces[i.GetNamespace()])
+	var o Ownership
+	for _, c := range candidates {
+		if o.Application == "" {
+			o.Application = c.Application
+		}
+		if o.Owner == "" {
+			o.Owner = c.Owner
+		}
+	}
+	return o
+}
+
+// ResolveNamespace returns the ownership of a namespace
+func (r *OwnershipResolver) ResolveNamespace(name string) Ownership {
+	return r.namespaces[name]
+}
+
+// SetOwnership writes the ownership into the data of the object
+func SetOwnership(o *KubernetesObject, ownership Ownership) {
+	switch d := o.Data.(type) {
+	case *Workload:
+		d.LeanixApplication = ownership.Application
+		d.Owner = ownership.Owner
+	case map[string]interface{}:
+		if ownership.Application != "" {
+			d[ApplicationField] = ownership.Application
+		}
+		if ownership.Owner != "" {
+			d[OwnerField] = ownership.Owner
+		}
+	}
+}
+
+func lookup(i *unstructured.Unstructured, sources []OwnershipSource) string {
+	for _, s := range sources {
+		var values map[string]string
+		if s.Type == LabelSource {
+			values = i.GetLabels()
+		} else {
+			values = i.GetAnnotations()
+		}
+		if v := values[s.Key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
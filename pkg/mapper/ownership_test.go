@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseOwnershipSources(t *testing.T) {
+	sources, err := ParseOwnershipSources([]string{"annotation:leanix.net/factsheet-id, label:app.kubernetes.io/part-of"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []OwnershipSource{
+		{Type: AnnotationSource, Key: "leanix.net/factsheet-id"},
+		{Type: LabelSource, Key: "app.kubernetes.io/part-of"},
+	}, sources)
+}
+
+func TestParseOwnershipSource_invalid(t *testing.T) {
+	for _, input := range []string{"team", "label:", "field:team"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseOwnershipSource(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOwnershipResolver(t *testing.T) {
+	application, err := ParseOwnershipSources(DefaultApplicationSources)
+	assert.NoError(t, err)
+	owner, err := ParseOwnershipSources([]string{"label:team"})
+	assert.NoError(t, err)
+	namespace := owned("v1", "Namespace", "shop", "ns", nil)
+	namespace.SetLabels(map[string]string{"team": "checkout", "app.kubernetes.io/part-of": "shop"})
+	deployment := owned("apps/v1", "Deployment", "web", "1", nil)
+	deployment.SetNamespace("shop")
+	deployment.SetAnnotations(map[string]string{"leanix.net/factsheet-id": "28fe4aa2-6e46-41a1-a131-72afb3acf256"})
+	replicaSet := owned("apps/v1", "ReplicaSet", "web-5d4f", "2", deployment)
+	replicaSet.SetNamespace("shop")
+	pod := owned("v1", "Pod", "web-5d4f-x7k2", "3", replicaSet)
+	pod.SetNamespace("shop")
+	pod.SetLabels(map[string]string{"team": "payments"})
+	other := owned("v1", "Service", "other", "4", nil)
+	other.SetNamespace("blog")
+	owners := NewOwnerGraph()
+	r := NewOwnershipResolver(OwnershipMapping{Application: application, Owner: owner}, owners)
+	for _, i := range []*unstructured.Unstructured{namespace, deployment, replicaSet, pod, other} {
+		owners.Add(i)
+		r.Add(i)
+	}
+
+	assert.Equal(t, Ownership{Application: "28fe4aa2-6e46-41a1-a131-72afb3acf256", Owner: "payments"}, r.Resolve(pod))
+	assert.Equal(t, Ownership{Application: "28fe4aa2-6e46-41a1-a131-72afb3acf256", Owner: "checkout"}, r.Resolve(replicaSet))
+	assert.Equal(t, Ownership{}, r.Resolve(other))
+	assert.Equal(t, Ownership{Application: "shop", Owner: "checkout"}, r.ResolveNamespace("shop"))
+}
+
+func TestSetOwnership(t *testing.T) {
+	ownership := Ownership{Application: "shop", Owner: "checkout"}
+	raw := KubernetesObject{Data: map[string]interface{}{}}
+	workload := KubernetesObject{Data: &Workload{}}
+
+	SetOwnership(&raw, ownership)
+	SetOwnership(&workload, ownership)
+
+	assert.Equal(t, map[string]interface{}{ApplicationField: "shop", OwnerField: "checkout"}, raw.Data)
+	assert.Equal(t, "shop", workload.Data.(*Workload).LeanixApplication)
+	assert.Equal(t, "checkout", workload.Data.(*Workload).Owner)
+}
@@ -0,0 +1,78 @@
+package mapper
+
+import (
+	"github.com/leanix/leanix-k8s-connector/pkg/fieldpath"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultProjectionExclude is the list of fields removed from every object when no exclude list is configured
+var DefaultProjectionExclude = []string{
+	"*:metadata.managedFields",
+	"*:metadata.resourceVersion",
+	"*:metadata.selfLink",
+	"*:status.conditions",
+}
+
+// identityPaths are kept by every projection that includes fields, so the object can still be identified
+var identityPaths = []fieldpath.Path{
+	mustParsePath("apiVersion"),
+	mustParsePath("kind"),
+	mustParsePath("metadata.name"),
+	mustParsePath("metadata.namespace"),
+	mustParsePath("metadata.uid"),
+}
+
+func mustParsePath(s string) fieldpath.Path {
+	p, err := fieldpath.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Projection selects the fields of the objects that are written to the LDIF.
+// If include paths are configured for a kind, only those fields and the identity fields are kept.
+// Excluded fields are removed afterwards.
+type Projection struct {
+	Include []fieldpath.KindPath
+	Exclude []fieldpath.KindPath
+	// KeepRaw adds the unprojected object to the KubernetesObject for debugging
+	KeepRaw bool
+}
+
+// Project returns the projected object of the given kind. The given object is not modified, as it is still
+// referenced by the owner graph, the ownership resolver and the relation builder.
+func (p Projection) Project(kind string, obj map[string]interface{}) map[string]interface{} {
+	include := make([]fieldpath.Path, 0)
+	for _, i := range p.Include {
+		if i.Matches(kind) {
+			include = append(include, i.Path)
+		}
+	}
+	var projected map[string]interface{}
+	if len(include) > 0 {
+		projected = fieldpath.Project(obj, append(include, identityPaths...))
+	} else {
+		projected = runtime.DeepCopyJSON(obj)
+	}
+	for _, e := range p.Exclude {
+		if e.Matches(kind) {
+			e.Path.Delete(projected)
+		}
+	}
+	return projected
+}
+
+// MapObject maps a Kubernetes object into a KubernetesObject with the projected fields as data
+func MapObject(i *unstructured.Unstructured, projection Projection) KubernetesObject {
+	o := KubernetesObject{
+		Type: i.GetKind(),
+		ID:   string(i.GetUID()),
+		Data: projection.Project(i.GetKind(), i.Object),
+	}
+	if projection.KeepRaw {
+		o.Raw = i.Object
+	}
+	return o
+}
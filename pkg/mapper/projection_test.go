@@ -0,0 +1,104 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/fieldpath"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func deployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "nginx",
+				"namespace":       "default",
+				"uid":             "b1b2",
+				"resourceVersion": "42",
+				"labels":          map[string]interface{}{"app": "nginx"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(2),
+			},
+			"status": map[string]interface{}{
+				"replicas":   int64(2),
+				"conditions": []interface{}{map[string]interface{}{"type": "Available"}},
+			},
+		},
+	}
+}
+
+func TestMapObject(t *testing.T) {
+	defaultExclude, err := fieldpath.ParseKindPaths(DefaultProjectionExclude)
+	assert.NoError(t, err)
+	include, err := fieldpath.ParseKindPaths([]string{"Deployment:metadata.labels", "Deployment:spec.replicas", "Pod:spec"})
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		projection Projection
+		expected   map[string]interface{}
+	}{
+		"default exclude": {
+			projection: Projection{Exclude: defaultExclude},
+			expected: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "nginx",
+					"namespace": "default",
+					"uid":       "b1b2",
+					"labels":    map[string]interface{}{"app": "nginx"},
+				},
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"replicas": int64(2)},
+			},
+		},
+		"include": {
+			projection: Projection{Include: include, Exclude: defaultExclude},
+			expected: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "nginx",
+					"namespace": "default",
+					"uid":       "b1b2",
+					"labels":    map[string]interface{}{"app": "nginx"},
+				},
+				"spec": map[string]interface{}{"replicas": int64(2)},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := MapObject(deployment(), tt.projection)
+
+			assert.Equal(t, "Deployment", o.Type)
+			assert.Equal(t, "b1b2", o.ID)
+			assert.Equal(t, tt.expected, o.Data)
+			assert.Nil(t, o.Raw)
+		})
+	}
+}
+
+func TestMapObject_keepRaw(t *testing.T) {
+	exclude, err := fieldpath.ParseKindPaths(DefaultProjectionExclude)
+	assert.NoError(t, err)
+
+	o := MapObject(deployment(), Projection{Exclude: exclude, KeepRaw: true})
+
+	assert.Equal(t, deployment().Object, o.Raw)
+	assert.NotContains(t, o.Data.(map[string]interface{})["metadata"], "resourceVersion")
+}
+
+func TestMapObject_doesNotModifyObject(t *testing.T) {
+	exclude, err := fieldpath.ParseKindPaths(DefaultProjectionExclude)
+	assert.NoError(t, err)
+	d := deployment()
+
+	o := MapObject(d, Projection{Exclude: exclude})
+
+	assert.Equal(t, deployment().Object, d.Object)
+	assert.NotContains(t, o.Data.(map[string]interface{})["metadata"], "resourceVersion")
+}
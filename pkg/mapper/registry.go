@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ObjectMapper maps an object into the data of a KubernetesObject
+type ObjectMapper func(i *unstructured.Unstructured) (interface{}, error)
+
+// Registry holds the typed mappers by group and kind
+type Registry map[schema.GroupKind]ObjectMapper
+
+// NewRegistry returns a registry with the typed mappers for workloads
+func NewRegistry() Registry {
+	return Registry{
+		{Group: "apps", Kind: "Deployment"}:       MapDeployment,
+		{Group: "extensions", Kind: "Deployment"}: MapDeployment,
+		{Group: "apps", Kind: "StatefulSet"}:      MapStatefulSet,
+		{Group: "apps", Kind: "DaemonSet"}:        MapDaemonSet,
+		{Group: "extensions", Kind: "DaemonSet"}:  MapDaemonSet,
+		{Group: "batch", Kind: "Job"}:             MapJob,
+		{Group: "batch", Kind: "CronJob"}:         MapCronJob,
+	}
+}
+
+// Map maps an object with the typed mapper registered for its group and kind.
+// Objects of unknown kinds are mapped with MapObject. The projection only applies to those objects.
+func (r Registry) Map(i *unstructured.Unstructured, projection Projection) (KubernetesObject, error) {
+	m, ok := r[i.GroupVersionKind().GroupKind()]
+	if !ok {
+		return MapObject(i, projection), nil
+	}
+	data, err := m(i)
+	if err != nil {
+		return KubernetesObject{}, err
+	}
+	o := KubernetesObject{
+		Type: i.GetKind(),
+		ID:   string(i.GetUID()),
+		Data: data,
+	}
+	if projection.KeepRaw {
+		o.Raw = i.Object
+	}
+	return o, nil
+}
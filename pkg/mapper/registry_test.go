@@ -0,0 +1,149 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func podTemplate() map[string]interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"initContainers": []interface{}{
+				map[string]interface{}{"name": "migrate", "image": "app:1.0"},
+			},
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":  "app",
+					"image": "registry.example.com/team/app:1.0",
+					"env":   []interface{}{map[string]interface{}{"name": "PASSWORD", "value": "<redacted>"}},
+					"resources": map[string]interface{}{
+						"requests": map[string]interface{}{"cpu": "250m", "memory": "64Mi"},
+						"limits":   map[string]interface{}{"cpu": "1", "memory": "128Mi"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRegistryMap(t *testing.T) {
+	tests := map[string]struct {
+		object   map[string]interface{}
+		replicas *int32
+		schedule string
+	}{
+		"deployment": {
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(3), "template": podTemplate()},
+			},
+			replicas: int32Ptr(3),
+		},
+		"statefulset": {
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"spec":       map[string]interface{}{"replicas": int64(2), "template": podTemplate()},
+			},
+			replicas: int32Ptr(2),
+		},
+		"daemonset": {
+			object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"spec":       map[string]interface{}{"template": podTemplate()},
+				"status":     map[string]interface{}{"desiredNumberScheduled": int64(5)},
+			},
+			replicas: int32Ptr(5),
+		},
+		"job": {
+			object: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "Job",
+				"spec":       map[string]interface{}{"parallelism": int64(1), "template": podTemplate()},
+			},
+			replicas: int32Ptr(1),
+		},
+		"cronjob": {
+			object: map[string]interface{}{
+				"apiVersion": "batch/v1beta1",
+				"kind":       "CronJob",
+				"spec": map[string]interface{}{
+					"schedule":    "*/5 * * * *",
+					"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{"template": podTemplate()}},
+				},
+			},
+			schedule: "*/5 * * * *",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			controller := true
+			i := &unstructured.Unstructured{Object: tt.object}
+			i.SetName("app")
+			i.SetNamespace("default")
+			i.SetUID("b1b2")
+			i.SetLabels(map[string]string{"app": "app"})
+			i.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Parent", Name: "parent", UID: "a1a2", Controller: &controller}})
+
+			o, err := NewRegistry().Map(i, Projection{})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.object["kind"], o.Type)
+			assert.Equal(t, "b1b2", o.ID)
+			w := o.Data.(*Workload)
+			assert.Equal(t, "app", w.Name)
+			assert.Equal(t, "default", w.Namespace)
+			assert.Equal(t, map[string]string{"app": "app"}, w.Labels)
+			assert.Equal(t, tt.replicas, w.Replicas)
+			assert.Equal(t, tt.schedule, w.Schedule)
+			assert.Equal(t, []Owner{{Kind: "Parent", Name: "parent", UID: "a1a2", Controller: true}}, w.Owners)
+			assert.Equal(t, []Container{
+				{
+					Name:  "migrate",
+					Init:  true,
+					Image: Image{Name: "app:1.0", Registry: "docker.io", Repository: "library/app", Tag: "1.0"},
+				},
+				{
+					Name:     "app",
+					Image:    Image{Name: "registry.example.com/team/app:1.0", Registry: "registry.example.com", Repository: "team/app", Tag: "1.0"},
+					Requests: Resources{CPUMillicores: 250, MemoryBytes: 64 * 1024 * 1024},
+					Limits:   Resources{CPUMillicores: 1000, MemoryBytes: 128 * 1024 * 1024},
+				},
+			}, w.Containers)
+		})
+	}
+}
+
+func TestRegistryMap_unknownKind(t *testing.T) {
+	i := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "nginx", "uid": "b1b2"},
+	}}
+
+	o, err := NewRegistry().Map(i, Projection{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, i.Object, o.Data)
+}
+
+func TestRegistryMap_invalidObject(t *testing.T) {
+	i := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec":       map[string]interface{}{"replicas": "<redacted>"},
+	}}
+
+	_, err := NewRegistry().Map(i, Projection{})
+
+	assert.Error(t, err)
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,258 @@
+package mapper
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RelationType is the type of the relation objects in the LDIF
+const RelationType string = "Relation"
+
+// Relation types between objects
+const (
+	// SelectsRelation relates a Service to the workloads whose pod template matches its selector
+	SelectsRelation string = "selects"
+	// RoutesToRelation relates an Ingress to the Services of its backends
+	RoutesToRelation string = "routesTo"
+	// ScalesRelation relates a HorizontalPodAutoscaler to its scale target
+	ScalesRelation string = "scales"
+	// BindsToRelation relates a PersistentVolumeClaim to its PersistentVolume
+	BindsToRelation string = "bindsTo"
+	// UsesStorageClassRelation relates a PersistentVolumeClaim to its StorageClass
+	UsesStorageClassRelation string = "usesStorageClass"
+	// GrantsRelation relates a RoleBinding or ClusterRoleBinding to its ServiceAccount subjects
+	GrantsRelation string = "grants"
+)
+
+// Relation is the data of a relation object
+type Relation struct {
+	Type   string      `json:"type"`
+	Source RelationEnd `json:"source"`
+	Target RelationEnd `json:"target"`
+}
+
+// RelationEnd identifies the source or target of a relation. The id is the id of the object in the LDIF.
+type RelationEnd struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// workloadTemplatePaths are the paths of the pod template labels by kind
+var workloadTemplatePaths = map[string][]string{
+	"Deployment":            {"spec", "template", "metadata", "labels"},
+	"StatefulSet":           {"spec", "template", "metadata", "labels"},
+	"DaemonSet":             {"spec", "template", "metadata", "labels"},
+	"ReplicaSet":            {"spec", "template", "metadata", "labels"},
+	"ReplicationController": {"spec", "template", "metadata", "labels"},
+	"Job":                   {"spec", "template", "metadata", "labels"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "metadata", "labels"},
+}
+
+// RelationBuilder derives the relations between the collected objects. It only keeps the fields relations
+// are derived from, not the objects themselves.
+type RelationBuilder struct {
+	objects map[string]*relationObject
+	keys    []string
+	// workloads holds the keys of the objects with pod templates by namespace
+	workloads map[string][]string
+}
+
+// relationObject holds the fields of an object that relations are derived from
+type relationObject struct {
+	end RelationEnd
+	// selector is the pod selector of a Service
+	selector map[string]string
+	// templateLabels are the pod template labels of a workload without controller
+	templateLabels map[string]string
+	// targets are the objects referenced by the object
+	targets []relationTarget
+}
+
+// relationTarget is an object referenced by another object, that is related if it was collected
+type relationTarget struct {
+	typ       string
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewRelationBuilder creates an empty RelationBuilder
+func NewRelationBuilder() *RelationBuilder {
+	return &RelationBuilder{
+		objects:   make(map[string]*relationObject),
+		workloads: make(map[string][]string),
+	}
+}
+
+// Add adds an object to the builder
+func (b *RelationBuilder) Add(i *unstructured.Unstructured) {
+	k := objectKey(i.GetKind(), i.GetNamespace(), i.GetName())
+	if _, ok := b.objects[k]; !ok {
+		b.keys = append(b.keys, k)
+		if _, ok := workloadTemplatePaths[i.GetKind()]; ok {
+			b.workloads[i.GetNamespace()] = append(b.workloads[i.GetNamespace()], k)
+		}
+	}
+	b.objects[k] = newRelationObject(i)
+}
+
+// Relations returns a relation object for every relation between the collected objects sorted by id.
+// Relations to objects that were not collected are omitted.
+func (b *RelationBuilder) Relations() []KubernetesObject {
+	relations := make(map[string]Relation)
+	add := func(typ string, source *relationObject, targetKind string, targetNamespace string, targetName string) {
+		target, ok := b.objects[objectKey(targetKind, targetNamespace, targetName)]
+		if !ok {
+			return
+		}
+		r := Relation{Type: typ, Source: source.end, Target: target.end}
+		relations[strings.Join([]string{typ, r.Source.ID, r.Target.ID}, "/")] = r
+	}
+	for _, k := range b.keys {
+		o := b.objects[k]
+		for _, w := range b.selectedWorkloads(o) {
+			add(SelectsRelation, o, w.end.Kind, w.end.Namespace, w.end.Name)
+		}
+		for _, t := range o.targets {
+			add(t.typ, o, t.kind, t.namespace, t.name)
+		}
+	}
+
+	objects := make([]KubernetesObject, 0, len(relations))
+	for id, r := range relations {
+		objects = append(objects, KubernetesObject{
+			Type: RelationType,
+			ID:   id,
+			Data: r,
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ID < objects[j].ID
+	})
+	return objects
+}
+
+// newRelationObject reads the fields relations are derived from
+func newRelationObject(i *unstructured.Unstructured) *relationObject {
+	o := &relationObject{
+		end: RelationEnd{
+			ID:        string(i.GetUID()),
+			Kind:      i.GetKind(),
+			Namespace: i.GetNamespace(),
+			Name:      i.GetName(),
+		},
+	}
+	target := func(typ string, kind string, namespace string, name string) {
+		o.targets = append(o.targets, relationTarget{typ: typ, kind: kind, namespace: namespace, name: name})
+	}
+	if path, ok := workloadTemplatePaths[i.GetKind()]; ok && controllerOf(i.GetOwnerReferences()) == nil {
+		o.templateLabels, _, _ = unstructured.NestedStringMap(i.Object, path...)
+	}
+	switch i.GetKind() {
+	case "Service":
+		o.selector, _, _ = unstructured.NestedStringMap(i.Object, "spec", "selector")
+	case "Ingress":
+		for _, service := range ingressServices(i) {
+			target(RoutesToRelation, "Service", i.GetNamespace(), service)
+		}
+	case "HorizontalPodAutoscaler":
+		kind, _, _ := unstructured.NestedString(i.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ := unstructured.NestedString(i.Object, "spec", "scaleTargetRef", "name")
+		target(ScalesRelation, kind, i.GetNamespace(), name)
+	case "PersistentVolumeClaim":
+		if volume, _, _ := unstructured.NestedString(i.Object, "spec", "volumeName"); volume != "" {
+			target(BindsToRelation, "PersistentVolume", "", volume)
+		}
+		if class, _, _ := unstructured.NestedString(i.Object, "spec", "storageClassName"); class != "" {
+			target(UsesStorageClassRelation, "StorageClass", "", class)
+		}
+	case "RoleBinding", "ClusterRoleBinding":
+		subjects, _, _ := unstructured.NestedSlice(i.Object, "subjects")
+		for _, s := range subjects {
+			subject, ok := s.(map[string]interface{})
+			if !ok || subject["kind"] != "ServiceAccount" {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(subject, "name")
+			namespace, _, _ := unstructured.NestedString(subject, "namespace")
+			if namespace == "" {
+				namespace = i.GetNamespace()
+			}
+			target(GrantsRelation, "ServiceAccount", namespace, name)
+		}
+	}
+	return o
+}
+
+// selectedWorkloads returns the workloads without controller in the namespace of the service
+// whose pod template labels match the selector of the service
+func (b *RelationBuilder) selectedWorkloads(service *relationObject) []*relationObject {
+	if len(service.selector) == 0 {
+		return nil
+	}
+	workloads := make([]*relationObject, 0)
+	for _, k := range b.workloads[service.end.Namespace] {
+		w := b.objects[k]
+		if w.templateLabels == nil {
+			continue
+		}
+		if matchesSelector(service.selector, w.templateLabels) {
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads
+}
+
+// ingressServices returns the names of the backend services of an ingress in the extensions/v1beta1,
+// networking.k8s.io/v1beta1 or networking.k8s.io/v1 format
+func ingressServices(ingress *unstructured.Unstructured) []string {
+	services := make([]string, 0)
+	addBackend := func(backend map[string]interface{}) {
+		if name, _, _ := unstructured.NestedString(backend, "serviceName"); name != "" {
+			services = append(services, name)
+		}
+		if name, _, _ := unstructured.NestedString(backend, "service", "name"); name != "" {
+			services = append(services, name)
+		}
+	}
+	for _, field := range []string{"backend", "defaultBackend"} {
+		if backend, ok, _ := unstructured.NestedMap(ingress.Object, "spec", field); ok {
+			addBackend(backend)
+		}
+	}
+	rules, _, _ := unstructured.NestedSlice(ingress.Object, "spec", "rules")
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, p := range paths {
+			path, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if backend, ok, _ := unstructured.NestedMap(path, "backend"); ok {
+				addBackend(backend)
+			}
+		}
+	}
+	return services
+}
+
+func matchesSelector(selector map[string]string, labels map[string]string) bool {
+	for k, v := range selector {
+		if l, ok := labels[k]; !ok || l != v {
+			return false
+		}
+	}
+	return true
+}
+
+func objectKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
@@ -0,0 +1,105 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func object(kind string, namespace string, name string, uid string, fields map[string]interface{}) *unstructured.Unstructured {
+	i := &unstructured.Unstructured{Object: fields}
+	i.SetKind(kind)
+	i.SetNamespace(namespace)
+	i.SetName(name)
+	i.SetUID(types.UID(uid))
+	return i
+}
+
+func TestRelationBuilder(t *testing.T) {
+	deployment := object("Deployment", "shop", "web", "1", map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "web", "tier": "frontend"},
+		}}},
+	})
+	replicaSet := owned("apps/v1", "ReplicaSet", "web-5d4f", "2", deployment)
+	replicaSet.SetNamespace("shop")
+	replicaSet.Object["spec"] = deployment.Object["spec"]
+	otherNamespace := object("Deployment", "blog", "web", "3", map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "web"},
+		}}},
+	})
+	service := object("Service", "shop", "web", "4", map[string]interface{}{
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}},
+	})
+	ingress := object("Ingress", "shop", "web", "5", map[string]interface{}{
+		"spec": map[string]interface{}{"rules": []interface{}{
+			map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+				map[string]interface{}{"backend": map[string]interface{}{"service": map[string]interface{}{"name": "web"}}},
+				map[string]interface{}{"backend": map[string]interface{}{"serviceName": "missing"}},
+			}}},
+		}},
+	})
+	hpa := object("HorizontalPodAutoscaler", "shop", "web", "6", map[string]interface{}{
+		"spec": map[string]interface{}{"scaleTargetRef": map[string]interface{}{"kind": "Deployment", "name": "web"}},
+	})
+	pvc := object("PersistentVolumeClaim", "shop", "data", "7", map[string]interface{}{
+		"spec": map[string]interface{}{"volumeName": "pv-1", "storageClassName": "standard"},
+	})
+	pv := object("PersistentVolume", "", "pv-1", "8", map[string]interface{}{})
+	storageClass := object("StorageClass", "", "standard", "9", map[string]interface{}{})
+	serviceAccount := object("ServiceAccount", "shop", "web", "10", map[string]interface{}{})
+	roleBinding := object("RoleBinding", "shop", "web", "11", map[string]interface{}{
+		"subjects": []interface{}{
+			map[string]interface{}{"kind": "ServiceAccount", "name": "web"},
+			map[string]interface{}{"kind": "User", "name": "jane"},
+		},
+	})
+	b := NewRelationBuilder()
+	for _, i := range []*unstructured.Unstructured{deployment, replicaSet, otherNamespace, service, ingress, hpa, pvc, pv, storageClass, serviceAccount, roleBinding} {
+		b.Add(i)
+	}
+
+	relations := b.Relations()
+
+	ids := make([]string, len(relations))
+	for i, r := range relations {
+		ids[i] = r.ID
+		assert.Equal(t, RelationType, r.Type)
+	}
+	assert.Equal(t, []string{
+		"bindsTo/7/8",
+		"grants/11/10",
+		"routesTo/5/4",
+		"scales/6/1",
+		"selects/4/1",
+		"usesStorageClass/7/9",
+	}, ids)
+	assert.Equal(t, Relation{
+		Type:   SelectsRelation,
+		Source: RelationEnd{ID: "4", Kind: "Service", Namespace: "shop", Name: "web"},
+		Target: RelationEnd{ID: "1", Kind: "Deployment", Namespace: "shop", Name: "web"},
+	}, relations[4].Data)
+}
+
+func TestRelationBuilder_objectsModifiedAfterAdd(t *testing.T) {
+	service := object("Service", "shop", "web", "1", map[string]interface{}{
+		"spec": map[string]interface{}{"selector": map[string]interface{}{"app": "web"}},
+	})
+	deployment := object("Deployment", "shop", "web", "2", map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "web"},
+		}}},
+	})
+	b := NewRelationBuilder()
+	b.Add(service)
+	b.Add(deployment)
+
+	// e.g. redacted or projected before the relations are built
+	delete(service.Object, "spec")
+	delete(deployment.Object, "spec")
+
+	assert.Len(t, b.Relations(), 1)
+}
@@ -5,6 +5,8 @@ type KubernetesObject struct {
 	Type string      `json:"type,omitempty"`
 	ID   string      `json:"id,omitempty"`
 	Data interface{} `json:"data,omitempty"`
+	// Raw is the unprojected object, it is only set for debugging
+	Raw interface{} `json:"raw,omitempty"`
 }
 
 // CustomFields describes an object containing customized fields
@@ -15,14 +17,54 @@ type CustomFields struct {
 
 // LDIF (LEAN Data Interchange Format) represents the output file generated by the connector
 type LDIF struct {
-	ConnectorID         string             `json:"connectorId,omitempty"`
-	ConnectorType       string             `json:"connectorType,omitempty"`
-	ConnectorVersion    string             `json:"connectorVersion,omitempty"`
-	ProcessingDirection string             `json:"processingDirection,omitempty"`
-	ProcessingMode      string             `json:"processingMode,omitempty"`
-	LxVersion           string             `json:"lxVersion,omitempty"`
-	LxWorkspace         string             `json:"lxWorkspace,omitempty"`
-	Description         string             `json:"description,omitempty"`
-	CustomFields        CustomFields       `json:"customFields,omitempty"`
-	Content             []KubernetesObject `json:"content,omitempty"`
+	ConnectorID         string       `json:"connectorId,omitempty"`
+	ConnectorType       string       `json:"connectorType,omitempty"`
+	ConnectorVersion    string       `json:"connectorVersion,omitempty"`
+	ProcessingDirection string       `json:"processingDirection,omitempty"`
+	ProcessingMode      string       `json:"processingMode,omitempty"`
+	LxVersion           string       `json:"lxVersion,omitempty"`
+	LxWorkspace         string       `json:"lxWorkspace,omitempty"`
+	Description         string       `json:"description,omitempty"`
+	CustomFields        CustomFields `json:"customFields,omitempty"`
+	Content             Objects      `json:"content,omitempty"`
+}
+
+// Objects is a sequence of objects, that may be too large to be held in memory. It can be iterated repeatedly
+// and yields the objects in the same order every time.
+type Objects interface {
+	// Each calls fn for every object in order and stops at the first error
+	Each(fn func(o KubernetesObject) error) error
+}
+
+// ObjectList is a sequence of objects held in memory
+type ObjectList []KubernetesObject
+
+// Each calls fn for every object of the list
+func (l ObjectList) Each(fn func(o KubernetesObject) error) error {
+	for _, o := range l {
+		if err := fn(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObjectsFunc adapts an iteration function to the Objects interface
+type ObjectsFunc func(fn func(o KubernetesObject) error) error
+
+// Each calls f with fn
+func (f ObjectsFunc) Each(fn func(o KubernetesObject) error) error {
+	return f(fn)
+}
+
+// Concat returns the objects of all given sequences one after the other
+func Concat(objects ...Objects) Objects {
+	return ObjectsFunc(func(fn func(o KubernetesObject) error) error {
+		for _, o := range objects {
+			if err := o.Each(fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -0,0 +1,166 @@
+package mapper
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Workload is the normalized data of Deployments, StatefulSets, DaemonSets, Jobs and CronJobs
+type Workload struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Replicas is the desired number of pods. It is the number of scheduled pods for DaemonSets
+	// and the parallelism for Jobs. CronJobs have no replicas.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Schedule is the cron schedule of CronJobs
+	Schedule   string      `json:"schedule,omitempty"`
+	Containers []Container `json:"containers"`
+	// Owners are the owner references of the workload, e.g. the CronJob owning a Job
+	Owners []Owner `json:"owners,omitempty"`
+	// Controller is the top-level controller resolved from the owner references, e.g. the CronJob of a Job
+	Controller *ObjectReference `json:"controller,omitempty"`
+	// LeanixApplication and Owner are resolved from the labels and annotations of the workload,
+	// its controllers and its namespace
+	LeanixApplication string `json:"leanixApplication,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	CreationTimestamp string `json:"creationTimestamp"`
+}
+
+// Container is a container of the pod template of a workload
+type Container struct {
+	Name string `json:"name"`
+	// Init is true for init containers
+	Init     bool      `json:"init,omitempty"`
+	Image    Image     `json:"image"`
+	Requests Resources `json:"requests"`
+	Limits   Resources `json:"limits"`
+}
+
+// Resources are the compute resources requested by or limited for a container
+type Resources struct {
+	CPUMillicores int64 `json:"cpuMillicores,omitempty"`
+	MemoryBytes   int64 `json:"memoryBytes,omitempty"`
+}
+
+// Owner references the owner of an object
+type Owner struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	Controller bool   `json:"controller,omitempty"`
+}
+
+// MapDeployment maps a Deployment into a Workload
+func MapDeployment(i *unstructured.Unstructured) (interface{}, error) {
+	var d appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &d); err != nil {
+		return nil, err
+	}
+	w := mapWorkload(d.ObjectMeta, d.Spec.Template.Spec)
+	w.Replicas = d.Spec.Replicas
+	return w, nil
+}
+
+// MapStatefulSet maps a StatefulSet into a Workload
+func MapStatefulSet(i *unstructured.Unstructured) (interface{}, error) {
+	var s appsv1.StatefulSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &s); err != nil {
+		return nil, err
+	}
+	w := mapWorkload(s.ObjectMeta, s.Spec.Template.Spec)
+	w.Replicas = s.Spec.Replicas
+	return w, nil
+}
+
+// MapDaemonSet maps a DaemonSet into a Workload
+func MapDaemonSet(i *unstructured.Unstructured) (interface{}, error) {
+	var d appsv1.DaemonSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &d); err != nil {
+		return nil, err
+	}
+	w := mapWorkload(d.ObjectMeta, d.Spec.Template.Spec)
+	w.Replicas = &d.Status.DesiredNumberScheduled
+	return w, nil
+}
+
+// MapJob maps a Job into a Workload
+func MapJob(i *unstructured.Unstructured) (interface{}, error) {
+	var j batchv1.Job
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &j); err != nil {
+		return nil, err
+	}
+	w := mapWorkload(j.ObjectMeta, j.Spec.Template.Spec)
+	w.Replicas = j.Spec.Parallelism
+	return w, nil
+}
+
+// MapCronJob maps a CronJob into a Workload
+func MapCronJob(i *unstructured.Unstructured) (interface{}, error) {
+	var c batchv1beta1.CronJob
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(i.Object, &c); err != nil {
+		return nil, err
+	}
+	w := mapWorkload(c.ObjectMeta, c.Spec.JobTemplate.Spec.Template.Spec)
+	w.Schedule = c.Spec.Schedule
+	return w, nil
+}
+
+func mapWorkload(meta metav1.ObjectMeta, spec corev1.PodSpec) *Workload {
+	w := &Workload{
+		Name:              meta.Name,
+		Namespace:         meta.Namespace,
+		Labels:            meta.Labels,
+		Containers:        make([]Container, 0, len(spec.InitContainers)+len(spec.Containers)),
+		Owners:            mapOwners(meta.OwnerReferences),
+		CreationTimestamp: meta.CreationTimestamp.UTC().Format(time.RFC3339),
+	}
+	for _, c := range spec.InitContainers {
+		container := mapContainer(c)
+		container.Init = true
+		w.Containers = append(w.Containers, container)
+	}
+	for _, c := range spec.Containers {
+		w.Containers = append(w.Containers, mapContainer(c))
+	}
+	return w
+}
+
+func mapContainer(c corev1.Container) Container {
+	return Container{
+		Name:     c.Name,
+		Image:    ParseImage(c.Image),
+		Requests: mapResources(c.Resources.Requests),
+		Limits:   mapResources(c.Resources.Limits),
+	}
+}
+
+func mapResources(r corev1.ResourceList) Resources {
+	return Resources{
+		CPUMillicores: r.Cpu().MilliValue(),
+		MemoryBytes:   r.Memory().Value(),
+	}
+}
+
+func mapOwners(refs []metav1.OwnerReference) []Owner {
+	if len(refs) == 0 {
+		return nil
+	}
+	owners := make([]Owner, len(refs))
+	for i, r := range refs {
+		owners[i] = Owner{
+			Kind:       r.Kind,
+			Name:       r.Name,
+			UID:        string(r.UID),
+			Controller: r.Controller != nil && *r.Controller,
+		}
+	}
+	return owners
+}
@@ -0,0 +1,115 @@
+package redact
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/fieldpath"
+)
+
+const (
+	// Mask replaces the selected values with MaskedValue
+	Mask string = "mask"
+	// Remove deletes the selected fields. Selected array elements are masked instead.
+	Remove string = "remove"
+)
+
+// MaskedValue replaces the values selected by masking rules
+const MaskedValue string = "<redacted>"
+
+// Rule redacts the fields selected by the path in all objects of the kind
+type Rule struct {
+	fieldpath.KindPath
+	Action string
+}
+
+// DefaultRules are applied to every object before the user defined rules
+var DefaultRules = []Rule{
+	{fieldpath.MustParseKindPath("*:metadata.managedFields"), Remove},
+	{fieldpath.MustParseKindPath("*:metadata.annotations['kubectl.kubernetes.io/last-applied-configuration']"), Remove},
+	{fieldpath.MustParseKindPath("*:..env[*].value"), Mask},
+	{fieldpath.MustParseKindPath("ConfigMap:data.*"), Mask},
+	{fieldpath.MustParseKindPath("ConfigMap:binaryData.*"), Mask},
+	{fieldpath.MustParseKindPath("Secret:data.*"), Mask},
+	{fieldpath.MustParseKindPath("Secret:stringData.*"), Mask},
+}
+
+// ParseRules parses a list of masking rules in the format kind:path.
+// Every item may contain multiple comma separated rules.
+func ParseRules(entries []string) ([]Rule, error) {
+	paths, err := fieldpath.ParseKindPaths(entries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redaction rules: %s", err)
+	}
+	rules := make([]Rule, len(paths))
+	for i, p := range paths {
+		rules[i] = Rule{KindPath: p, Action: Mask}
+	}
+	return rules, nil
+}
+
+// Redaction counts the fields a rule redacted in objects of a kind
+type Redaction struct {
+	Kind   string `json:"kind"`
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Count  int    `json:"count"`
+}
+
+// Redactor applies redaction rules to objects and records the redactions
+type Redactor struct {
+	rules      []Rule
+	redactions map[string]*Redaction
+}
+
+// NewRedactor creates a redactor applying the given rules in order
+func NewRedactor(rules []Rule) *Redactor {
+	return &Redactor{
+		rules:      rules,
+		redactions: make(map[string]*Redaction),
+	}
+}
+
+// Redact modifies the object of the given kind in place
+func (r *Redactor) Redact(kind string, obj map[string]interface{}) {
+	for _, rule := range r.rules {
+		if !rule.Matches(kind) {
+			continue
+		}
+		count := rule.Path.Walk(obj, func(container interface{}, key string, index int) {
+			switch c := container.(type) {
+			case map[string]interface{}:
+				if rule.Action == Remove {
+					delete(c, key)
+				} else {
+					c[key] = MaskedValue
+				}
+			case []interface{}:
+				c[index] = MaskedValue
+			}
+		})
+		if count == 0 {
+			continue
+		}
+		id := kind + " " + rule.String()
+		if _, ok := r.redactions[id]; !ok {
+			r.redactions[id] = &Redaction{Kind: kind, Rule: rule.String(), Action: rule.Action}
+		}
+		r.redactions[id].Count += count
+	}
+}
+
+// Report returns the redactions sorted by kind and rule
+func (r *Redactor) Report() []Redaction {
+	report := make([]Redaction, 0, len(r.redactions))
+	for _, redaction := range r.redactions {
+		report = append(report, *redaction)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Kind != report[j].Kind {
+			return report[i].Kind < report[j].Kind
+		}
+		return report[i].Rule < report[j].Rule
+	})
+	return report
+}
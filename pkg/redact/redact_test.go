@@ -0,0 +1,90 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactDefaultRules(t *testing.T) {
+	deployment := map[string]interface{}{
+		"kind": "Deployment",
+		"metadata": map[string]interface{}{
+			"name":          "nginx",
+			"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"deployment.kubernetes.io/revision":                "1",
+			},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{
+							"name": "nginx",
+							"env": []interface{}{
+								map[string]interface{}{"name": "PASSWORD", "value": "secret"},
+								map[string]interface{}{"name": "TOKEN", "valueFrom": map[string]interface{}{}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	configMap := map[string]interface{}{
+		"kind": "ConfigMap",
+		"data": map[string]interface{}{"password": "secret", "user": "admin"},
+	}
+	r := NewRedactor(DefaultRules)
+
+	r.Redact("Deployment", deployment)
+	r.Redact("ConfigMap", configMap)
+
+	assert.Equal(t, map[string]interface{}{
+		"name": "nginx",
+		"annotations": map[string]interface{}{
+			"deployment.kubernetes.io/revision": "1",
+		},
+	}, deployment["metadata"])
+	env := deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["env"]
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "PASSWORD", "value": MaskedValue},
+		map[string]interface{}{"name": "TOKEN", "valueFrom": map[string]interface{}{}},
+	}, env)
+	assert.Equal(t, map[string]interface{}{"password": MaskedValue, "user": MaskedValue}, configMap["data"])
+	assert.Equal(t, []Redaction{
+		{Kind: "ConfigMap", Rule: "ConfigMap:data.*", Action: Mask, Count: 2},
+		{Kind: "Deployment", Rule: "*:..env[*].value", Action: Mask, Count: 1},
+		{Kind: "Deployment", Rule: "*:metadata.annotations['kubectl.kubernetes.io/last-applied-configuration']", Action: Remove, Count: 1},
+		{Kind: "Deployment", Rule: "*:metadata.managedFields", Action: Remove, Count: 1},
+	}, r.Report())
+}
+
+func TestRedactUserRules(t *testing.T) {
+	rules, err := ParseRules([]string{"Pod:metadata.labels.owner,*:spec.hosts[1]"})
+	assert.NoError(t, err)
+	pod := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"owner": "jane", "app": "nginx"}},
+		"spec":     map[string]interface{}{"hosts": []interface{}{"a", "b"}},
+	}
+	service := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"owner": "jane"}},
+	}
+	r := NewRedactor(rules)
+
+	r.Redact("Pod", pod)
+	r.Redact("Service", service)
+
+	assert.Equal(t, map[string]interface{}{"owner": MaskedValue, "app": "nginx"}, pod["metadata"].(map[string]interface{})["labels"])
+	assert.Equal(t, []interface{}{"a", MaskedValue}, pod["spec"].(map[string]interface{})["hosts"])
+	assert.Equal(t, map[string]interface{}{"owner": "jane"}, service["metadata"].(map[string]interface{})["labels"])
+	assert.Len(t, r.Report(), 2)
+}
+
+func TestParseRules_invalid(t *testing.T) {
+	_, err := ParseRules([]string{"Pod:metadata.name,metadata.name"})
+
+	assert.Error(t, err)
+}
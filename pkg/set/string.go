@@ -1,15 +1,23 @@
 package set
 
+import (
+	"sort"
+)
+
 // String is a helper type to represent a set of strings
 type String struct {
 	Map map[string]bool
 }
 
-// NewStringSet new StringSet with an empty set
-func NewStringSet() *String {
-	return &String{
-		Map: make(map[string]bool),
+// NewStringSet new StringSet containing the given items
+func NewStringSet(items ...string) *String {
+	s := &String{
+		Map: make(map[string]bool, len(items)),
+	}
+	for _, i := range items {
+		s.Add(i)
 	}
+	return s
 }
 
 // Add adds a string to the set
@@ -17,7 +25,17 @@ func (s *String) Add(i string) {
 	s.Map[i] = true
 }
 
-// Items returns all items in the set as slice
+// Remove removes a string from the set
+func (s *String) Remove(i string) {
+	delete(s.Map, i)
+}
+
+// Len returns the number of items in the set
+func (s *String) Len() int {
+	return len(s.Map)
+}
+
+// Items returns all items in the set as sorted slice
 func (s *String) Items() []string {
 	slice := make([]string, len(s.Map))
 	i := 0
@@ -25,6 +43,7 @@ func (s *String) Items() []string {
 		slice[i] = k
 		i++
 	}
+	sort.Strings(slice)
 	return slice
 }
 
@@ -33,3 +52,37 @@ func (s *String) Contains(input string) bool {
 	_, ok := s.Map[input]
 	return ok
 }
+
+// Union returns a new set with the items contained in either set
+func (s *String) Union(other *String) *String {
+	union := NewStringSet()
+	for k := range s.Map {
+		union.Add(k)
+	}
+	for k := range other.Map {
+		union.Add(k)
+	}
+	return union
+}
+
+// Intersect returns a new set with the items contained in both sets
+func (s *String) Intersect(other *String) *String {
+	intersection := NewStringSet()
+	for k := range s.Map {
+		if other.Contains(k) {
+			intersection.Add(k)
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new set with the items of the set that are not contained in the other set
+func (s *String) Difference(other *String) *String {
+	difference := NewStringSet()
+	for k := range s.Map {
+		if !other.Contains(k) {
+			difference.Add(k)
+		}
+	}
+	return difference
+}
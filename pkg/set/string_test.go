@@ -28,9 +28,7 @@ func TestStringSetList(t *testing.T) {
 
 	list := s.Items()
 
-	assert.Len(t, list, 2)
-	assert.Contains(t, list, "foo")
-	assert.Contains(t, list, "bar")
+	assert.Equal(t, []string{"bar", "foo"}, list)
 }
 
 func TestStringSetContains_containsString(t *testing.T) {
@@ -50,3 +48,50 @@ func TestStringSetContains_notContainsString(t *testing.T) {
 
 	assert.Equal(t, false, found)
 }
+
+func TestNewStringSet_withItems(t *testing.T) {
+	s := NewStringSet("foo", "bar", "foo")
+
+	assert.Equal(t, 2, s.Len())
+}
+
+func TestStringSetRemove(t *testing.T) {
+	s := NewStringSet("foo", "bar")
+
+	s.Remove("foo")
+	s.Remove("test")
+
+	assert.Equal(t, []string{"bar"}, s.Items())
+}
+
+func TestStringSetOperations(t *testing.T) {
+	tests := map[string]struct {
+		operation func(a *String, b *String) *String
+		expected  []string
+	}{
+		"union": {
+			operation: (*String).Union,
+			expected:  []string{"a", "b", "c", "d"},
+		},
+		"intersect": {
+			operation: (*String).Intersect,
+			expected:  []string{"b", "c"},
+		},
+		"difference": {
+			operation: (*String).Difference,
+			expected:  []string{"a"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := NewStringSet("a", "b", "c")
+			b := NewStringSet("b", "c", "d")
+
+			result := tt.operation(a, b)
+
+			assert.Equal(t, tt.expected, result.Items())
+			assert.Equal(t, 3, a.Len())
+			assert.Equal(t, 3, b.Len())
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
 
 	"github.com/Azure/azure-storage-blob-go/azblob"
@@ -50,14 +52,17 @@ func NewAzureBlob(azureOpts *AzureBlobOpts) (*AzureContainer, error) {
 	return u, nil
 }
 
-// Upload uploads the LDIF file to azure blob storage
-func (u *AzureContainer) UploadLdif(ldif []byte) error {
-	err := u.uploadFile(LdifFileName, ldif)
-	if err != nil {
+// LdifWriter returns a writer that streams the LDIF file as block blob to azure blob storage
+func (u *AzureContainer) LdifWriter(name string) (LdifWriter, error) {
+	blobURL := azblob.ContainerURL(*u.Container).NewBlockBlobURL(name)
+	return NewStreamWriter(func(r io.Reader) error {
+		ctx := context.Background()
+		_, err := azblob.UploadStreamToBlockBlob(ctx, r, blobURL, azblob.UploadStreamToBlockBlobOptions{
+			BufferSize: 4 * 1024 * 1024,
+			MaxBuffers: 2,
+		})
 		return err
-	}
-
-	return nil
+	}), nil
 }
 
 // Upload uploads the log file to azure blob storage
@@ -70,6 +75,27 @@ func (u *AzureContainer) UploadLog(log []byte) error {
 	return nil
 }
 
+// UploadState uploads the state file to azure blob storage
+func (u *AzureContainer) UploadState(state []byte) error {
+	return u.uploadFile(StateFileName, state)
+}
+
+// DownloadState downloads the state file from azure blob storage
+func (u *AzureContainer) DownloadState() ([]byte, error) {
+	return u.downloadFile(StateFileName)
+}
+
+// UploadLdifHash uploads the hash of the LDIF content to azure blob storage
+func (u *AzureContainer) UploadLdifHash(hash string) error {
+	return u.uploadFile(LdifHashFileName, []byte(hash))
+}
+
+// DownloadLdifHash downloads the hash of the previous LDIF content from azure blob storage
+func (u *AzureContainer) DownloadLdifHash() (string, error) {
+	hash, err := u.downloadFile(LdifHashFileName)
+	return string(hash), err
+}
+
 func (u *AzureContainer) uploadFile(name string, content []byte) error {
 	blobURL := azblob.ContainerURL(*u.Container).NewBlockBlobURL(name)
 
@@ -78,3 +104,20 @@ func (u *AzureContainer) uploadFile(name string, content []byte) error {
 
 	return err
 }
+
+// downloadFile returns the content of the blob or nil if the blob does not exist
+func (u *AzureContainer) downloadFile(name string) ([]byte, error) {
+	blobURL := azblob.ContainerURL(*u.Container).NewBlockBlobURL(name)
+
+	ctx := context.Background()
+	resp, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		if storageErr, ok := err.(azblob.StorageError); ok && storageErr.ServiceCode() == azblob.ServiceCodeBlobNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	body := resp.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"io"
 )
 
 const (
@@ -10,16 +11,38 @@ const (
 	AzureBlobStorage string = "azureblob"
 	// FileStorage is a constant for the file storage identifier
 	FileStorage string = "file"
-	// LdifFileName is a constant for the file name used to store the ldif content
+	// LdifFileName is a constant for the file name used to store the ldif content containing all objects
 	LdifFileName string = "kubernetes.ldif"
+	// DeltaLdifFileName is a constant for the file name used to store the ldif content of a delta sync,
+	// that only contains the objects changed since the previous run
+	DeltaLdifFileName string = "kubernetes-delta.ldif"
 	// LogFileName is a constant for the file name used to store the log output
 	LogFileName string = "leanix-k8s-connector.log"
+	// LdifHashFileName is a constant for the file name used to store the hash of the ldif content
+	LdifHashFileName string = "kubernetes.ldif.sha256"
+	// StateFileName is a constant for the file name used to store the state of the previous run
+	StateFileName string = "leanix-k8s-connector.state"
 )
 
+// LdifWriter streams the LDIF content to a storage backend or the Integration API
+type LdifWriter interface {
+	io.WriteCloser
+	// Abort discards everything written so far instead of persisting it. The previously stored content is kept.
+	Abort(err error) error
+}
+
 // Backend exposes a common interface for all storage mechanisms
 type Backend interface {
-	UploadLdif(ldif []byte) error
+	// LdifWriter returns a writer to stream the LDIF content to the file with the given name in the backend.
+	// The content is persisted once the writer is closed successfully and discarded if it is aborted.
+	LdifWriter(name string) (LdifWriter, error)
+	UploadLdifHash(hash string) error
+	// DownloadLdifHash returns the hash stored alongside the previous ldif or an empty string if there is none
+	DownloadLdifHash() (string, error)
 	UploadLog(log []byte) error
+	UploadState(state []byte) error
+	// DownloadState returns the state stored by the previous run or nil if there is none
+	DownloadState() ([]byte, error)
 }
 
 // NewBackend create a new storage backend for the given storage backend type
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+)
+
+const (
+	contentPrefix = "    "
+	indent        = "  "
+)
+
+// LdifEncoder writes an LDIF document object by object to a writer, so the content does not have to be
+// held in memory as a whole. The output is identical to json.MarshalIndent of the LDIF with an indent of two spaces.
+type LdifEncoder struct {
+	w       io.Writer
+	objects int
+	header  bool
+	closed  bool
+}
+
+// NewLdifEncoder creates a new LdifEncoder writing to w
+func NewLdifEncoder(w io.Writer) *LdifEncoder {
+	return &LdifEncoder{
+		w: w,
+	}
+}
+
+// WriteHeader writes all fields of the LDIF except the content. It must be called before any object is encoded.
+func (e *LdifEncoder) WriteHeader(ldif mapper.LDIF) error {
+	if e.header {
+		return errors.New("LDIF header already written")
+	}
+	ldif.Content = nil
+	header, err := json.MarshalIndent(ldif, "", indent)
+	if err != nil {
+		return err
+	}
+	// strip the closing brace of the header object and open the content array instead
+	header = bytes.TrimSuffix(bytes.TrimSuffix(header, []byte("}")), []byte("\n"))
+	if len(header) > 1 {
+		header = append(header, ',')
+	}
+	header = append(header, "\n"+indent+`"content": [`...)
+	_, err = e.w.Write(header)
+	if err != nil {
+		return err
+	}
+	e.header = true
+	return nil
+}
+
+// Encode writes a single object to the content of the LDIF
+func (e *LdifEncoder) Encode(o mapper.KubernetesObject) error {
+	if !e.header || e.closed {
+		return errors.New("LDIF objects must be encoded between WriteHeader and Close")
+	}
+	object, err := json.MarshalIndent(o, contentPrefix, indent)
+	if err != nil {
+		return err
+	}
+	separator := ",\n" + contentPrefix
+	if e.objects == 0 {
+		separator = "\n" + contentPrefix
+	}
+	_, err = e.w.Write(append([]byte(separator), object...))
+	if err != nil {
+		return err
+	}
+	e.objects++
+	return nil
+}
+
+// Close terminates the LDIF document. It does not close the underlying writer.
+func (e *LdifEncoder) Close() error {
+	if !e.header {
+		return errors.New("LDIF header not written")
+	}
+	if e.closed {
+		return nil
+	}
+	trailer := "]\n}"
+	if e.objects > 0 {
+		trailer = "\n" + indent + trailer
+	}
+	_, err := io.WriteString(e.w, trailer)
+	if err != nil {
+		return err
+	}
+	e.closed = true
+	return nil
+}
+
+// EncodeLdif writes the complete LDIF to w using an LdifEncoder
+func EncodeLdif(w io.Writer, ldif mapper.LDIF) error {
+	encoder := NewLdifEncoder(w)
+	err := encoder.WriteHeader(ldif)
+	if err != nil {
+		return err
+	}
+	if ldif.Content != nil {
+		err = ldif.Content.Each(encoder.Encode)
+		if err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
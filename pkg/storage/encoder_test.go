@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLdif(content []mapper.KubernetesObject) mapper.LDIF {
+	return mapper.LDIF{
+		ConnectorID:    "Kubernetes",
+		ConnectorType:  "leanix-k8s-connector",
+		ProcessingMode: "full",
+		LxWorkspace:    "00000000-0000-0000-0000-000000000000",
+		Description:    "Map Kubernetes objects to LeanIX Fact Sheets <&>",
+		CustomFields: mapper.CustomFields{
+			ConnectorInstance: "aks-cluster",
+		},
+		Content: mapper.ObjectList(content),
+	}
+}
+
+func TestEncodeLdif(t *testing.T) {
+	ldif := testLdif([]mapper.KubernetesObject{
+		mapper.KubernetesObject{
+			ID:   "aks-cluster",
+			Type: "Cluster",
+			Data: map[string]interface{}{
+				"nodeTypes": []string{"Standard_D2s_v3"},
+			},
+		},
+		mapper.KubernetesObject{
+			ID:   "b1b2",
+			Type: "Pod",
+			Data: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":   "nginx",
+					"labels": map[string]interface{}{"app": "nginx"},
+				},
+			},
+		},
+	})
+	expected, err := json.MarshalIndent(ldif, "", "  ")
+	assert.NoError(t, err)
+	var b bytes.Buffer
+
+	err = EncodeLdif(&b, ldif)
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), b.String())
+}
+
+func TestEncodeLdif_emptyContent(t *testing.T) {
+	var b bytes.Buffer
+
+	err := EncodeLdif(&b, testLdif(nil))
+
+	assert.NoError(t, err)
+	var decoded struct {
+		CustomFields mapper.CustomFields       `json:"customFields"`
+		Content      []mapper.KubernetesObject `json:"content"`
+	}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &decoded))
+	assert.Equal(t, "aks-cluster", decoded.CustomFields.ConnectorInstance)
+	assert.Empty(t, decoded.Content)
+}
+
+func TestLdifEncoder_encodeBeforeHeader(t *testing.T) {
+	var b bytes.Buffer
+	encoder := NewLdifEncoder(&b)
+
+	err := encoder.Encode(mapper.KubernetesObject{ID: "foo"})
+
+	assert.Error(t, err)
+	assert.Error(t, encoder.Close())
+}
+
+func TestLdifEncoder_encodeAfterClose(t *testing.T) {
+	var b bytes.Buffer
+	encoder := NewLdifEncoder(&b)
+	assert.NoError(t, encoder.WriteHeader(testLdif(nil)))
+	assert.NoError(t, encoder.Close())
+
+	err := encoder.Encode(mapper.KubernetesObject{ID: "foo"})
+
+	assert.Error(t, err)
+}
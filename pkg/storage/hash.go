@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+)
+
+// Hash returns the hex encoded SHA-256 hash of the content of the LDIF. The header fields are not part of the
+// hash, as they may change on every run. The encoding sorts map keys, so equal and equally ordered objects
+// always produce the same hash.
+func Hash(ldif mapper.LDIF) (string, error) {
+	h := sha256.New()
+	encoder := json.NewEncoder(h)
+	if ldif.Content != nil {
+		err := ldif.Content.Each(func(o mapper.KubernetesObject) error {
+			return encoder.Encode(o)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
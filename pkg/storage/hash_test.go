@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash(t *testing.T) {
+	pod := func(image string) mapper.KubernetesObject {
+		return mapper.KubernetesObject{
+			ID:   "b1b2",
+			Type: "Pod",
+			Data: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "nginx", "labels": map[string]interface{}{"app": "nginx", "tier": "web"}},
+				"spec":     map[string]interface{}{"image": image},
+			},
+		}
+	}
+
+	hash, err := Hash(testLdif([]mapper.KubernetesObject{pod("nginx:1.19")}))
+	assert.NoError(t, err)
+	same, err := Hash(testLdif([]mapper.KubernetesObject{pod("nginx:1.19")}))
+	assert.NoError(t, err)
+	changed, err := Hash(testLdif([]mapper.KubernetesObject{pod("nginx:1.21")}))
+	assert.NoError(t, err)
+	header := testLdif([]mapper.KubernetesObject{pod("nginx:1.19")})
+	header.CustomFields.BuildVersion = "1.1.0"
+	headerChanged, err := Hash(header)
+	assert.NoError(t, err)
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, same)
+	assert.NotEqual(t, hash, changed)
+	assert.Equal(t, hash, headerChanged, "header fields are not hashed")
+}
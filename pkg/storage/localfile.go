@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bufio"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -32,13 +33,18 @@ func NewLocalFile(path string) (*LocalFile, error) {
 	return lf, nil
 }
 
-// Upload persists the ldif content in a local files
-func (u *LocalFile) UploadLdif(ldif []byte) error {
-	err := ioutil.WriteFile(path.Join(u.Path, LdifFileName), ldif, 0644)
+// LdifWriter returns a writer to a temporary file, that replaces the ldif file when the writer is closed
+func (u *LocalFile) LdifWriter(name string) (LdifWriter, error) {
+	name = path.Join(u.Path, name)
+	f, err := os.Create(name + ".tmp")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return &localFileWriter{
+		Writer: bufio.NewWriter(f),
+		file:   f,
+		name:   name,
+	}, nil
 }
 
 // Upload persists the the log file content in a local files
@@ -49,3 +55,62 @@ func (u *LocalFile) UploadLog(log []byte) error {
 	}
 	return nil
 }
+
+// UploadLdifHash persists the hash of the ldif content in a local file
+func (u *LocalFile) UploadLdifHash(hash string) error {
+	return ioutil.WriteFile(path.Join(u.Path, LdifHashFileName), []byte(hash), 0644)
+}
+
+// DownloadLdifHash reads the hash of the previous ldif content from a local file
+func (u *LocalFile) DownloadLdifHash() (string, error) {
+	hash, err := ioutil.ReadFile(path.Join(u.Path, LdifHashFileName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(hash), err
+}
+
+// UploadState persists the state content in a local file
+func (u *LocalFile) UploadState(state []byte) error {
+	return ioutil.WriteFile(path.Join(u.Path, StateFileName), state, 0644)
+}
+
+// DownloadState reads the state content from a local file
+func (u *LocalFile) DownloadState() ([]byte, error) {
+	state, err := ioutil.ReadFile(path.Join(u.Path, StateFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return state, err
+}
+
+type localFileWriter struct {
+	*bufio.Writer
+	file *os.File
+	name string
+}
+
+// Close replaces the ldif file with the temporary file. The temporary file is removed if that fails.
+func (w *localFileWriter) Close() error {
+	err := w.Flush()
+	if err != nil {
+		w.Abort(err)
+		return err
+	}
+	err = w.file.Close()
+	if err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+	err = os.Rename(w.file.Name(), w.name)
+	if err != nil {
+		os.Remove(w.file.Name())
+	}
+	return err
+}
+
+// Abort removes the temporary file and leaves the ldif file untouched
+func (w *localFileWriter) Abort(err error) error {
+	w.file.Close()
+	return os.Remove(w.file.Name())
+}
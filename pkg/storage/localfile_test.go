@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalFileLdifWriter(t *testing.T) {
+	tests := map[string]struct {
+		finish   func(w LdifWriter) error
+		expected string
+	}{
+		"close replaces the ldif file": {
+			finish: func(w LdifWriter) error {
+				return w.Close()
+			},
+			expected: "new",
+		},
+		"abort keeps the previous ldif file": {
+			finish: func(w LdifWriter) error {
+				return w.Abort(errors.New("encoding failed"))
+			},
+			expected: "previous",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "localfile")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+			err = ioutil.WriteFile(path.Join(dir, LdifFileName), []byte("previous"), 0644)
+			assert.NoError(t, err)
+			backend, err := NewLocalFile(dir)
+			assert.NoError(t, err)
+
+			w, err := backend.LdifWriter(LdifFileName)
+			assert.NoError(t, err)
+			_, err = io.WriteString(w, "new")
+			assert.NoError(t, err)
+			assert.NoError(t, tt.finish(w))
+
+			ldif, err := ioutil.ReadFile(path.Join(dir, LdifFileName))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(ldif))
+			_, err = os.Stat(path.Join(dir, LdifFileName+".tmp"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
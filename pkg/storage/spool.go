@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Spool keeps mapped objects in temporary files instead of memory. Objects are appended to named sections
+// and read back in the order they were written. A Spool is not safe for concurrent use.
+type Spool struct {
+	dir      string
+	sections map[string]*spoolSection
+	files    int
+}
+
+type spoolSection struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// SpooledObject is a mapped object together with the identity of the Kubernetes object it was mapped from
+type SpooledObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	UID        string
+	Object     mapper.KubernetesObject
+}
+
+// spoolRecord is the serialized SpooledObject. The data is decoded into a Workload if it was one,
+// other data is decoded into generic values keeping the numbers as they were encoded. So the objects of the
+// mappers, whose data is a Workload or generic values, encode exactly like the objects that were written.
+type spoolRecord struct {
+	APIVersion string          `json:"apiVersion,omitempty"`
+	Kind       string          `json:"kind,omitempty"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	UID        string          `json:"uid,omitempty"`
+	Type       string          `json:"type,omitempty"`
+	ID         string          `json:"id,omitempty"`
+	Workload   bool            `json:"workload,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Raw        json.RawMessage `json:"raw,omitempty"`
+}
+
+// NewSpooledObject returns the mapped object o of the Kubernetes object i
+func NewSpooledObject(i *unstructured.Unstructured, o mapper.KubernetesObject) SpooledObject {
+	return SpooledObject{
+		APIVersion: i.GetAPIVersion(),
+		Kind:       i.GetKind(),
+		Namespace:  i.GetNamespace(),
+		Name:       i.GetName(),
+		UID:        string(i.GetUID()),
+		Object:     o,
+	}
+}
+
+// Source returns an object that only holds the identity of the Kubernetes object the object was mapped from,
+// e.g. to resolve its owners
+func (o SpooledObject) Source() *unstructured.Unstructured {
+	i := &unstructured.Unstructured{Object: make(map[string]interface{})}
+	i.SetAPIVersion(o.APIVersion)
+	i.SetKind(o.Kind)
+	i.SetNamespace(o.Namespace)
+	i.SetName(o.Name)
+	i.SetUID(types.UID(o.UID))
+	return i
+}
+
+// NewSpool creates a spool in a new temporary directory in dir. The default directory for temporary files
+// is used if dir is empty.
+func NewSpool(dir string) (*Spool, error) {
+	dir, err := ioutil.TempDir(dir, "leanix-k8s-connector-spool")
+	if err != nil {
+		return nil, err
+	}
+	return &Spool{
+		dir:      dir,
+		sections: make(map[string]*spoolSection),
+	}, nil
+}
+
+// Write appends an object to a section. The section is created with the first object.
+func (s *Spool) Write(section string, o SpooledObject) error {
+	sec, ok := s.sections[section]
+	if !ok {
+		f, err := os.Create(path.Join(s.dir, fmt.Sprintf("section-%d", s.files)))
+		if err != nil {
+			return err
+		}
+		s.files++
+		sec = &spoolSection{
+			file:   f,
+			writer: bufio.NewWriter(f),
+		}
+		s.sections[section] = sec
+	}
+	r := spoolRecord{
+		APIVersion: o.APIVersion,
+		Kind:       o.Kind,
+		Namespace:  o.Namespace,
+		Name:       o.Name,
+		UID:        o.UID,
+		Type:       o.Object.Type,
+		ID:         o.Object.ID,
+	}
+	_, r.Workload = o.Object.Data.(*mapper.Workload)
+	var err error
+	if o.Object.Data != nil {
+		r.Data, err = json.Marshal(o.Object.Data)
+		if err != nil {
+			return err
+		}
+	}
+	if o.Object.Raw != nil {
+		r.Raw, err = json.Marshal(o.Object.Raw)
+		if err != nil {
+			return err
+		}
+	}
+	return json.NewEncoder(sec.writer).Encode(r)
+}
+
+// Each calls fn for every object of a section in the order the objects were written.
+// A section without objects is empty.
+func (s *Spool) Each(section string, fn func(o SpooledObject) error) error {
+	sec, ok := s.sections[section]
+	if !ok {
+		return nil
+	}
+	err := sec.writer.Flush()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(sec.file.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var r spoolRecord
+		err = decoder.Decode(&r)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		o := SpooledObject{
+			APIVersion: r.APIVersion,
+			Kind:       r.Kind,
+			Namespace:  r.Namespace,
+			Name:       r.Name,
+			UID:        r.UID,
+			Object: mapper.KubernetesObject{
+				Type: r.Type,
+				ID:   r.ID,
+			},
+		}
+		if r.Workload {
+			w := &mapper.Workload{}
+			err = json.Unmarshal(r.Data, w)
+			o.Object.Data = w
+		} else {
+			o.Object.Data, err = decodeGeneric(r.Data)
+		}
+		if err != nil {
+			return err
+		}
+		o.Object.Raw, err = decodeGeneric(r.Raw)
+		if err != nil {
+			return err
+		}
+		err = fn(o)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Objects returns the mapped objects of a section
+func (s *Spool) Objects(section string) mapper.Objects {
+	return mapper.ObjectsFunc(func(fn func(o mapper.KubernetesObject) error) error {
+		return s.Each(section, func(o SpooledObject) error {
+			return fn(o.Object)
+		})
+	})
+}
+
+// Drop removes a section with all its objects
+func (s *Spool) Drop(section string) error {
+	sec, ok := s.sections[section]
+	if !ok {
+		return nil
+	}
+	delete(s.sections, section)
+	sec.file.Close()
+	return os.Remove(sec.file.Name())
+}
+
+// Close removes all sections and the temporary directory of the spool
+func (s *Spool) Close() error {
+	for _, sec := range s.sections {
+		sec.file.Close()
+	}
+	s.sections = make(map[string]*spoolSection)
+	return os.RemoveAll(s.dir)
+}
+
+// decodeGeneric decodes JSON into generic values and keeps numbers as json.Number
+func decodeGeneric(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	err := decoder.Decode(&v)
+	return v, err
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/leanix/leanix-k8s-connector/pkg/mapper"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSpool(t *testing.T) {
+	replicas := int32(2)
+	deployment := mapper.KubernetesObject{
+		Type: "Deployment",
+		ID:   "1",
+		Data: &mapper.Workload{Name: "nginx", Namespace: "default", Replicas: &replicas, Containers: []mapper.Container{}},
+	}
+	pod := mapper.KubernetesObject{
+		Type: "Pod",
+		ID:   "2",
+		Data: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "nginx-1", "generation": int64(9007199254740993)},
+			"spec":     map[string]interface{}{"priority": 0.5, "hostNetwork": false, "tolerations": []interface{}{}},
+		},
+		Raw: map[string]interface{}{"kind": "Pod"},
+	}
+	source := &unstructured.Unstructured{}
+	source.SetAPIVersion("apps/v1")
+	source.SetKind("Deployment")
+	source.SetNamespace("default")
+	source.SetName("nginx")
+	source.SetUID("1")
+	spool, err := NewSpool("")
+	assert.NoError(t, err)
+	defer spool.Close()
+
+	assert.NoError(t, spool.Write("deployments", NewSpooledObject(source, deployment)))
+	assert.NoError(t, spool.Write("pods", SpooledObject{Object: pod}))
+	assert.NoError(t, spool.Write("deployments", SpooledObject{Object: mapper.KubernetesObject{Type: "Deployment", ID: "3"}}))
+
+	spooled := make([]SpooledObject, 0)
+	err = spool.Each("deployments", func(o SpooledObject) error {
+		spooled = append(spooled, o)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, spooled, 2)
+	assert.Equal(t, source, spooled[0].Source())
+	assert.Equal(t, deployment, spooled[0].Object)
+	assert.Equal(t, "3", spooled[1].Object.ID)
+	assert.Nil(t, spooled[1].Object.Data)
+
+	pods := make([]mapper.KubernetesObject, 0)
+	err = spool.Objects("pods").Each(func(o mapper.KubernetesObject) error {
+		pods = append(pods, o)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	expected, err := json.Marshal(pod)
+	assert.NoError(t, err)
+	actual, err := json.Marshal(pods[0])
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual), "spooled objects encode like the written objects")
+}
+
+func TestSpoolDrop(t *testing.T) {
+	spool, err := NewSpool("")
+	assert.NoError(t, err)
+	defer spool.Close()
+	assert.NoError(t, spool.Write("pods", SpooledObject{Object: mapper.KubernetesObject{ID: "1"}}))
+	assert.NoError(t, spool.Write("secrets", SpooledObject{Object: mapper.KubernetesObject{ID: "2"}}))
+
+	assert.NoError(t, spool.Drop("pods"))
+	assert.NoError(t, spool.Write("services", SpooledObject{Object: mapper.KubernetesObject{ID: "3"}}))
+
+	ids := make([]string, 0)
+	for _, section := range []string{"pods", "secrets", "services", "unknown"} {
+		err = spool.Objects(section).Each(func(o mapper.KubernetesObject) error {
+			ids = append(ids, o.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"2", "3"}, ids)
+}
+
+func TestSpoolClose(t *testing.T) {
+	spool, err := NewSpool("")
+	assert.NoError(t, err)
+	assert.NoError(t, spool.Write("pods", SpooledObject{Object: mapper.KubernetesObject{ID: "1"}}))
+
+	assert.NoError(t, spool.Close())
+
+	_, err = os.Stat(spool.dir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"io"
+)
+
+// StreamWriter hands everything written to it as a stream to an upload function running in the background
+type StreamWriter struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+// NewStreamWriter starts upload in the background reading from the returned StreamWriter.
+// The upload finishes after the StreamWriter is closed.
+func NewStreamWriter(upload func(r io.Reader) error) *StreamWriter {
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := upload(pr)
+		// unblock pending writes in case the upload stopped reading early
+		pr.CloseWithError(err)
+		done <- err
+	}()
+	return &StreamWriter{
+		pipe: pw,
+		done: done,
+	}
+}
+
+// Write writes p to the upload stream
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	return s.pipe.Write(p)
+}
+
+// Close terminates the upload stream and waits for the upload to finish
+func (s *StreamWriter) Close() error {
+	s.pipe.Close()
+	return <-s.done
+}
+
+// Abort terminates the upload stream with err, so the upload fails instead of finishing with the content
+// written so far, and waits for the upload to return
+func (s *StreamWriter) Abort(err error) error {
+	s.pipe.CloseWithError(err)
+	<-s.done
+	return nil
+}
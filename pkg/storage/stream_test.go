@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamWriter(t *testing.T) {
+	encodeErr := errors.New("encoding failed")
+	tests := map[string]struct {
+		finish      func(w *StreamWriter) error
+		expectedErr error
+	}{
+		"close completes the upload": {
+			finish: func(w *StreamWriter) error {
+				return w.Close()
+			},
+		},
+		"abort fails the upload": {
+			finish: func(w *StreamWriter) error {
+				return w.Abort(encodeErr)
+			},
+			expectedErr: encodeErr,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var uploaded []byte
+			var uploadErr error
+			w := NewStreamWriter(func(r io.Reader) error {
+				uploaded, uploadErr = ioutil.ReadAll(r)
+				return uploadErr
+			})
+			_, err := io.WriteString(w, "partial")
+			assert.NoError(t, err)
+
+			err = tt.finish(w)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedErr, uploadErr)
+			assert.Equal(t, "partial", string(uploaded))
+		})
+	}
+}